@@ -0,0 +1,106 @@
+// Package catalog provides ready made parsers for common Linux command and procfs outputs.
+// Sizes are normalized to bytes and durations to seconds.
+//
+//	meminfo    /proc/meminfo
+//	diskstats  /proc/diskstats
+//	netdev     /proc/net/dev
+//	df         df -kP
+//	free       free -b
+//	vmstat     vmstat [interval]
+//	iostat     iostat -x [interval]
+//	uptime     uptime
+//	ss         ss -s
+//	lscpu      lscpu
+//
+// The parser definitions are embedded as YAML files in the definitions directory
+// and can be used as a starting point for custom definitions.
+package catalog
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brunotm/rexon"
+)
+
+//go:embed definitions/*.yaml
+var files embed.FS
+
+var (
+	loadOnce    sync.Once
+	loadErr     error
+	definitions map[string]*rexon.Definition
+)
+
+// Get returns a new Parser for the given name
+func Get(name string) (p *rexon.Parser, err error) {
+	if err = load(); err != nil {
+		return nil, err
+	}
+
+	d, ok := definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown parser: %s", name)
+	}
+
+	return d.Parser()
+}
+
+// MustGet is like Get but panics on error
+func MustGet(name string) (p *rexon.Parser) {
+	p, err := Get(name)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Names returns the sorted names of the available parsers
+func Names() (names []string) {
+	if load() != nil {
+		return nil
+	}
+
+	names = make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// load decodes the embedded parser definitions
+func load() (err error) {
+	loadOnce.Do(func() {
+		entries, err := files.ReadDir("definitions")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		definitions = make(map[string]*rexon.Definition, len(entries))
+		for _, entry := range entries {
+			file := path.Join("definitions", entry.Name())
+			data, err := files.ReadFile(file)
+			if err != nil {
+				loadErr = err
+				return
+			}
+
+			d, err := rexon.LoadDefinition(bytes.NewReader(data))
+			if err != nil {
+				loadErr = fmt.Errorf("error loading %s, %s", file, err.Error())
+				return
+			}
+
+			definitions[strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))] = d
+		}
+	})
+
+	return loadErr
+}
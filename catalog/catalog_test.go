@@ -0,0 +1,59 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the expected results in testdata")
+
+func TestCatalog(t *testing.T) {
+	names := Names()
+	if len(names) == 0 {
+		t.Fatal(load())
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", name+".txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var results bytes.Buffer
+			for d := range MustGet(name).ParseBytes(context.Background(), data) {
+				if d.Errors != nil {
+					t.Fatal(d.Errors)
+				}
+				results.Write(d.Data)
+				results.WriteByte('\n')
+			}
+
+			golden := filepath.Join("testdata", name+".json")
+			if *update {
+				if err = ioutil.WriteFile(golden, results.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(results.Bytes(), expected) {
+				t.Fatalf("expected:\n%s\ngot:\n%s", expected, results.Bytes())
+			}
+		})
+	}
+}
+
+func TestCatalogUnknown(t *testing.T) {
+	if _, err := Get("unknown"); err == nil {
+		t.Fatal("expected error for unknown parser")
+	}
+}
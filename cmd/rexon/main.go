@@ -0,0 +1,226 @@
+// Command rexon extracts newline delimited JSON documents from unstructured
+// data using a parser definition file.
+//
+// Usage:
+//
+//	rexon -f definition.json [file ...]
+//
+// Input is read from the given files or from stdin when no files are specified
+// or a file is "-". Each parsed document is written to stdout as a single JSON line,
+// and parse errors are written to stderr.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	// Embedded zoneinfo used for the definition locations when the system database is not available
+	_ "time/tzdata"
+
+	"github.com/brunotm/rexon"
+	"github.com/buger/jsonparser"
+)
+
+const (
+	exitOK    = 0
+	exitFatal = 1
+	exitUsage = 2
+)
+
+// options for writing the parsed results
+type options struct {
+	failFast  bool      // Stop at the first parse error
+	quiet     bool      // Do not write parse errors
+	meta      bool      // Add the source position metadata to each document
+	summary   bool      // Write the summary of the lines read to errOut
+	unmatched io.Writer // Write the unmatched lines, if set
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	cancel()
+	os.Exit(code)
+}
+
+// run executes the command with the given arguments and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) (code int) {
+	flags := flag.NewFlagSet("rexon", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: rexon -f definition [flags] [file ...]\n")
+		flags.PrintDefaults()
+	}
+
+	definition := flags.String("f", "", "parser definition file in JSON or YAML format")
+	errorExit := flags.Int("error-exit", 0, "exit code to use when parse errors occur, 0 ignores parse errors")
+	var opts options
+	flags.BoolVar(&opts.failFast, "fail-fast", false, "stop at the first parse error")
+	flags.BoolVar(&opts.quiet, "q", false, "do not write parse errors to stderr")
+	flags.BoolVar(&opts.meta, "meta", false, "add the source position metadata to each document as _meta")
+	flags.BoolVar(&opts.summary, "summary", false, "write the count of lines read, matched, skipped and unmatched to stderr")
+	deadLetter := flags.String("dead-letter", "", "file to write the unmatched lines")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *definition == "" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	parser, err := loadParser(*definition, *deadLetter != "", opts.summary)
+	if err != nil {
+		fmt.Fprintf(stderr, "rexon: %s\n", err)
+		return exitFatal
+	}
+
+	if *deadLetter != "" {
+		f, err := os.Create(*deadLetter)
+		if err != nil {
+			fmt.Fprintf(stderr, "rexon: %s\n", err)
+			return exitFatal
+		}
+		defer f.Close()
+		opts.unmatched = f
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	var parseErrors bool
+	for _, name := range files {
+		var ok bool
+		if name == "-" {
+			ok, err = parse(ctx, parser, "stdin", stdin, stdout, stderr, opts)
+		} else {
+			ok, err = parseFile(ctx, parser, name, stdout, stderr, opts)
+		}
+
+		if err != nil {
+			fmt.Fprintf(stderr, "rexon: %s\n", err)
+			return exitFatal
+		}
+
+		if !ok {
+			parseErrors = true
+			if opts.failFast {
+				break
+			}
+		}
+	}
+
+	if parseErrors && *errorExit != 0 {
+		return *errorExit
+	}
+
+	return exitOK
+}
+
+// parse writes each result data from the given input as a JSON line to out.
+// Reports false if any parse errors were found
+func parse(ctx context.Context, parser *rexon.Parser, name string, in io.Reader,
+	out, errOut io.Writer, opts options) (ok bool, err error) {
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ok = true
+	line := make([]byte, 0, 512)
+
+	for result := range parser.Parse(ctx, in) {
+		switch result.Kind {
+		case rexon.UnmatchedResult:
+			if opts.unmatched != nil {
+				line = append(line[:0], result.Meta.Raw...)
+				line = append(line, '\n')
+				if _, err = opts.unmatched.Write(line); err != nil {
+					return ok, err
+				}
+			}
+			continue
+
+		case rexon.SummaryResult:
+			if opts.summary {
+				fmt.Fprintf(errOut, "rexon: %s: lines=%d matched=%d skipped=%d unmatched=%d\n", name,
+					result.Summary.Lines, result.Summary.Matched, result.Summary.Skipped, result.Summary.Unmatched)
+			}
+			continue
+		}
+
+		if len(result.Errors) > 0 {
+			ok = false
+			if !opts.quiet {
+				for _, e := range result.Errors {
+					fmt.Fprintf(errOut, "rexon: %s: %s\n", name, e)
+				}
+			}
+
+			if opts.failFast {
+				return false, nil
+			}
+		}
+
+		if len(result.Data) == 0 {
+			continue
+		}
+
+		data := result.Data
+		if opts.meta {
+			meta, err := result.Meta.MarshalJSON()
+			if err != nil {
+				return ok, err
+			}
+			if data, err = jsonparser.Set(data, meta, "_meta"); err != nil {
+				return ok, err
+			}
+		}
+
+		line = append(line[:0], data...)
+		line = append(line, '\n')
+		if _, err = out.Write(line); err != nil {
+			return ok, err
+		}
+	}
+
+	return ok, parent.Err()
+}
+
+func parseFile(ctx context.Context, parser *rexon.Parser, name string,
+	out, errOut io.Writer, opts options) (ok bool, err error) {
+
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	return parse(ctx, parser, name, f, out, errOut, opts)
+}
+
+// loadParser loads the parser definition enabling the unmatched lines and summary reports
+func loadParser(path string, unmatched, summary bool) (parser *rexon.Parser, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d, err := rexon.LoadDefinition(f)
+	if err != nil {
+		return nil, err
+	}
+
+	d.ReportUnmatched = d.ReportUnmatched || unmatched
+	d.ReportSummary = d.ReportSummary || summary
+	return d.Parser()
+}
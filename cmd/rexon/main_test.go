@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testDefinition = `
+line_regex: '(\w+)\s+(\S+)'
+values:
+  - name: name
+    type: string
+  - name: value
+    type: number
+`
+	testInput = "a 1\nb 2.5\nc x\nd 4\n"
+)
+
+func writeDefinition(t *testing.T) (path string) {
+	dir, err := ioutil.TempDir("", "rexon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path = filepath.Join(dir, "definition.yaml")
+	if err = ioutil.WriteFile(path, []byte(testDefinition), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	def := writeDefinition(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-f", def}, strings.NewReader(testInput), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d", exitOK, code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d: %s", len(lines), stdout.String())
+	}
+
+	if lines[1] != `{"name":"b","value":2.5}` {
+		t.Fatalf("unexpected output: %s", lines[1])
+	}
+
+	if !strings.Contains(stderr.String(), "stdin") {
+		t.Fatalf("expected parse error on stderr, got: %s", stderr.String())
+	}
+}
+
+func TestRunErrorExit(t *testing.T) {
+	def := writeDefinition(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-f", def, "-error-exit", "3", "-fail-fast", "-q"},
+		strings.NewReader(testInput), &stdout, &stderr)
+	if code != 3 {
+		t.Fatalf("expected exit code 3, got %d", code)
+	}
+
+	if strings.Count(stdout.String(), "\n") != 2 {
+		t.Fatalf("expected output to stop at the first error, got: %s", stdout.String())
+	}
+
+	if stderr.Len() != 0 {
+		t.Fatalf("expected no output on stderr, got: %s", stderr.String())
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), nil, nil, &stdout, &stderr); code != exitUsage {
+		t.Fatalf("expected exit code %d, got %d", exitUsage, code)
+	}
+
+	code := run(context.Background(), []string{"-f", "nonexistent"}, nil, &stdout, &stderr)
+	if code != exitFatal {
+		t.Fatalf("expected exit code %d, got %d", exitFatal, code)
+	}
+}
+
+func TestRunMeta(t *testing.T) {
+	def := writeDefinition(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-f", def, "-meta", "-q"},
+		strings.NewReader(testInput), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d", exitOK, code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	expected := `{"name":"d","value":4,"_meta":{"first_line":4,"last_line":4,"start":14,"end":18}}`
+	if lines[3] != expected {
+		t.Fatalf("expected %s, got %s", expected, lines[3])
+	}
+}
+
+func TestRunDeadLetter(t *testing.T) {
+	def := writeDefinition(t)
+	deadLetter := filepath.Join(filepath.Dir(def), "unmatched.txt")
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-f", def, "-dead-letter", deadLetter, "-summary", "-q"},
+		strings.NewReader("a 1\n???\nb 2\n"), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d", exitOK, code)
+	}
+
+	data, err := ioutil.ReadFile(deadLetter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "???\n" {
+		t.Fatalf("unexpected unmatched lines: %q", data)
+	}
+
+	if stderr.String() != "rexon: stdin: lines=3 matched=2 skipped=0 unmatched=1\n" {
+		t.Fatalf("unexpected summary: %s", stderr.String())
+	}
+}
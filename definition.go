@@ -0,0 +1,567 @@
+package rexon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Definition is the serializable form of a Parser and its Values.
+// It can be decoded from JSON or YAML and built with Definition.Parser
+type Definition struct {
+	LineRegex       string              `json:"line_regex,omitempty" yaml:"line_regex,omitempty"`
+	FindAll         bool                `json:"find_all,omitempty" yaml:"find_all,omitempty"`
+	StartTag        string              `json:"start_tag,omitempty" yaml:"start_tag,omitempty"`
+	StopTag         string              `json:"stop_tag,omitempty" yaml:"stop_tag,omitempty"`
+	SkipTag         string              `json:"skip_tag,omitempty" yaml:"skip_tag,omitempty"`
+	ContinueTag     string              `json:"continue_tag,omitempty" yaml:"continue_tag,omitempty"`
+	TrimSpaces      bool                `json:"trim_spaces,omitempty" yaml:"trim_spaces,omitempty"`
+	RawText         bool                `json:"raw_text,omitempty" yaml:"raw_text,omitempty"`
+	OnError         ErrorPolicy         `json:"on_error,omitempty" yaml:"on_error,omitempty"`
+	ReportUnmatched bool                `json:"report_unmatched,omitempty" yaml:"report_unmatched,omitempty"`
+	ReportSummary   bool                `json:"report_summary,omitempty" yaml:"report_summary,omitempty"`
+	Table           *TableDefinition    `json:"table,omitempty" yaml:"table,omitempty"`
+	KeyValue        *KeyValueDefinition `json:"key_value,omitempty" yaml:"key_value,omitempty"`
+	Logfmt          *LogfmtDefinition   `json:"logfmt,omitempty" yaml:"logfmt,omitempty"`
+	Values          []*ValueDefinition  `json:"values" yaml:"values"`
+}
+
+// TableDefinition is the serializable form of the table mode options
+type TableDefinition struct {
+	HeaderTag     string            `json:"header_tag,omitempty" yaml:"header_tag,omitempty"`
+	ColumnNames   []string          `json:"column_names,omitempty" yaml:"column_names,omitempty"`
+	ColumnRenames map[string]string `json:"column_renames,omitempty" yaml:"column_renames,omitempty"`
+	FixedWidth    bool              `json:"fixed_width,omitempty" yaml:"fixed_width,omitempty"`
+	Columns       []ColumnSpec      `json:"columns,omitempty" yaml:"columns,omitempty"`
+	RuneOffsets   bool              `json:"rune_offsets,omitempty" yaml:"rune_offsets,omitempty"`
+	Headerless    bool              `json:"headerless,omitempty" yaml:"headerless,omitempty"`
+	Delimiter     string            `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+	Escape        string            `json:"escape,omitempty" yaml:"escape,omitempty"`
+}
+
+// KeyValueDefinition is the serializable form of the key value options.
+// The Value name is optional and its regexp is applied to the captured value
+type KeyValueDefinition struct {
+	Regex     string           `json:"regex" yaml:"regex"`
+	Value     *ValueDefinition `json:"value" yaml:"value"`
+	Normalize KeyFormat        `json:"normalize,omitempty" yaml:"normalize,omitempty"`
+	AllowKeys []string         `json:"allow_keys,omitempty" yaml:"allow_keys,omitempty"`
+}
+
+// LogfmtDefinition is the serializable form of the logfmt options, an empty object sets the logfmt mode
+type LogfmtDefinition struct {
+	Normalize KeyFormat `json:"normalize,omitempty" yaml:"normalize,omitempty"`
+	AllowKeys []string  `json:"allow_keys,omitempty" yaml:"allow_keys,omitempty"`
+}
+
+// ValueDefinition is the serializable form of a Value
+type ValueDefinition struct {
+	Name        string      `json:"name" yaml:"name"`
+	Type        ValueType   `json:"type" yaml:"type"`
+	Path        []string    `json:"path,omitempty" yaml:"path,omitempty"`
+	Regex       string      `json:"regex,omitempty" yaml:"regex,omitempty"`
+	Round       *int        `json:"round,omitempty" yaml:"round,omitempty"`
+	FromFormat  string      `json:"from_format,omitempty" yaml:"from_format,omitempty"`
+	ToFormat    string      `json:"to_format,omitempty" yaml:"to_format,omitempty"`
+	FromFormats []string    `json:"from_formats,omitempty" yaml:"from_formats,omitempty"`
+	Location    string      `json:"location,omitempty" yaml:"location,omitempty"`
+	ToLocation  string      `json:"to_location,omitempty" yaml:"to_location,omitempty"`
+	InferYear   bool        `json:"infer_year,omitempty" yaml:"infer_year,omitempty"`
+	ShortClock  ClockFormat `json:"short_clock,omitempty" yaml:"short_clock,omitempty"`
+	Nullable    bool        `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Required    bool        `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// DefinitionError reports an invalid field in a Definition.
+// Value and Index are only set when the error belongs to a ValueDefinition
+type DefinitionError struct {
+	Value string
+	Index int
+	Field string
+	Err   error
+}
+
+func (e *DefinitionError) Error() string {
+	if e.Value == "" && e.Index < 0 {
+		return fmt.Sprintf("invalid definition field %s: %s", e.Field, e.Err.Error())
+	}
+	return fmt.Sprintf("invalid definition field %s for values[%d] %q: %s", e.Field, e.Index, e.Value, e.Err.Error())
+}
+
+// LoadDefinition decodes a JSON or YAML Definition from the given reader.
+// Unknown fields are reported as errors
+func LoadDefinition(r io.Reader) (d *Definition, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d = &Definition{}
+	data = bytes.TrimSpace(data)
+
+	// YAML is a superset of JSON, but use the JSON decoder
+	// for JSON documents to get better error messages
+	if len(data) > 0 && data[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err = dec.Decode(d); err != nil {
+			return nil, fmt.Errorf("error decoding definition, %s", err.Error())
+		}
+		return d, nil
+	}
+
+	if err = yaml.UnmarshalStrict(data, d); err != nil {
+		return nil, fmt.Errorf("error decoding definition, %s", err.Error())
+	}
+	return d, nil
+}
+
+// LoadParser decodes, validates and builds a Parser from a JSON or YAML Definition
+func LoadParser(r io.Reader) (p *Parser, err error) {
+	d, err := LoadDefinition(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.Parser()
+}
+
+// Parser validates the definition and builds a new Parser
+func (d *Definition) Parser() (p *Parser, err error) {
+	var options []ParserOpt
+
+	if len(d.Values) == 0 && d.Table == nil && d.LineRegex == "" && d.KeyValue == nil && d.Logfmt == nil {
+		return nil, parserFieldError("values", fmt.Errorf("at least one value is required"))
+	}
+
+	if d.Table != nil && d.LineRegex != "" {
+		return nil, parserFieldError("table", fmt.Errorf("table cannot be used with line_regex"))
+	}
+
+	if d.KeyValue != nil && (d.Table != nil || d.LineRegex != "") {
+		return nil, parserFieldError("key_value", fmt.Errorf("key_value cannot be used with table or line_regex"))
+	}
+
+	if d.Logfmt != nil && (d.Table != nil || d.LineRegex != "" || d.KeyValue != nil) {
+		return nil, parserFieldError("logfmt", fmt.Errorf("logfmt cannot be used with table, key_value or line_regex"))
+	}
+
+	if (d.SkipTag == "") != (d.ContinueTag == "") {
+		return nil, parserFieldError("skip_tag", fmt.Errorf("skip_tag and continue_tag must be set together"))
+	}
+
+	if d.FindAll && d.LineRegex == "" {
+		return nil, parserFieldError("find_all", fmt.Errorf("find_all requires line_regex"))
+	}
+
+	tags := []struct {
+		field string
+		expr  string
+		opt   func(string) ParserOpt
+	}{
+		{"line_regex", d.LineRegex, LineRegex},
+		{"start_tag", d.StartTag, StartTag},
+		{"stop_tag", d.StopTag, StopTag},
+		{"skip_tag", d.SkipTag, SkipTag},
+		{"continue_tag", d.ContinueTag, ContinueTag},
+	}
+
+	for _, tag := range tags {
+		if tag.expr == "" {
+			continue
+		}
+		opt := tag.opt(tag.expr)
+		if err = opt(&Parser{}); err != nil {
+			return nil, parserFieldError(tag.field, err)
+		}
+		options = append(options, fieldOption(tag.field, opt))
+	}
+
+	if d.FindAll {
+		options = append(options, FindAll())
+	}
+
+	if d.TrimSpaces {
+		options = append(options, TrimSpaces())
+	}
+
+	if d.Logfmt != nil {
+		logfmtOpts, err := keyOptions("logfmt", d.Logfmt.Normalize, d.Logfmt.AllowKeys)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, fieldOption("logfmt", Logfmt()))
+		options = append(options, logfmtOpts...)
+	}
+
+	if d.RawText {
+		options = append(options, RawText())
+	}
+
+	if d.ReportUnmatched {
+		options = append(options, ReportUnmatched())
+	}
+
+	if d.ReportSummary {
+		options = append(options, ReportSummary())
+	}
+
+	if d.OnError != "" {
+		if d.OnError == SeparateErrors {
+			return nil, parserFieldError("on_error", fmt.Errorf("%s requires an error channel", d.OnError))
+		}
+		opt := OnError(d.OnError)
+		if err = opt(&Parser{}); err != nil {
+			return nil, parserFieldError("on_error", err)
+		}
+		options = append(options, fieldOption("on_error", opt))
+	}
+
+	if d.Table != nil {
+		tableOpts, err := d.Table.options()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, tableOpts...)
+	}
+
+	if d.KeyValue != nil {
+		kvOpts, err := d.KeyValue.options()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, kvOpts...)
+	}
+
+	names := map[string]bool{}
+	values := make([]*Value, 0, len(d.Values))
+	for i, vd := range d.Values {
+		if vd == nil {
+			return nil, &DefinitionError{Index: i, Field: "values", Err: fmt.Errorf("empty value definition")}
+		}
+
+		if names[vd.Name] {
+			return nil, vd.fieldError(i, "name", fmt.Errorf("duplicate value name"))
+		}
+		names[vd.Name] = true
+
+		v, err := vd.value(i)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	if p, err = NewParser(values, options...); err != nil {
+		if derr, ok := err.(*DefinitionError); ok {
+			return nil, derr
+		}
+		return nil, parserFieldError(d.modeField(), err)
+	}
+
+	return p, nil
+}
+
+// modeField returns the definition field of the parser mode
+func (d *Definition) modeField() (field string) {
+	switch {
+	case d.Table != nil:
+		return "table"
+	case d.KeyValue != nil:
+		return "key_value"
+	case d.Logfmt != nil:
+		return "logfmt"
+	case d.LineRegex != "":
+		return "line_regex"
+	}
+	return "values"
+}
+
+// fieldOption attributes the errors of a parser option to the given definition field
+func fieldOption(field string, opt ParserOpt) (o ParserOpt) {
+	return func(p *Parser) (err error) {
+		if err = opt(p); err != nil {
+			return parserFieldError(field, err)
+		}
+		return nil
+	}
+}
+
+// options validates the definition and builds the table mode options
+func (d *TableDefinition) options() (options []ParserOpt, err error) {
+	options = append(options, Table())
+
+	if d.HeaderTag != "" {
+		opt := HeaderTag(d.HeaderTag)
+		if err = opt(&Parser{}); err != nil {
+			return nil, parserFieldError("table.header_tag", err)
+		}
+		options = append(options, fieldOption("table.header_tag", opt))
+	}
+
+	if d.ColumnNames != nil {
+		if len(d.ColumnNames) == 0 {
+			return nil, parserFieldError("table.column_names", fmt.Errorf("empty column names"))
+		}
+		options = append(options, ColumnNames(d.ColumnNames...))
+	}
+
+	if d.Columns != nil {
+		if d.ColumnNames != nil {
+			return nil, parserFieldError("table.columns", fmt.Errorf("columns cannot be used with column_names"))
+		}
+		opt := Columns(d.Columns)
+		if err = opt(&Parser{}); err != nil {
+			return nil, parserFieldError("table.columns", err)
+		}
+		options = append(options, fieldOption("table.columns", opt))
+	}
+
+	if d.FixedWidth {
+		options = append(options, FixedWidth())
+	}
+
+	if d.RuneOffsets {
+		options = append(options, RuneOffsets())
+	}
+
+	if d.Headerless {
+		options = append(options, Headerless())
+	}
+
+	if d.Delimiter != "" {
+		if d.FixedWidth || d.Columns != nil {
+			return nil, parserFieldError("table.delimiter", fmt.Errorf("delimiter cannot be used with fixed width columns"))
+		}
+		options = append(options, Delimited(d.Delimiter))
+	}
+
+	if d.Escape != "" {
+		if len(d.Escape) != 1 || d.Delimiter == "" {
+			return nil, parserFieldError("table.escape", fmt.Errorf("escape must be a single character for a delimiter"))
+		}
+		opt := DelimiterEscape(d.Escape[0])
+		if err = opt(&Parser{}); err != nil {
+			return nil, parserFieldError("table.escape", err)
+		}
+		options = append(options, fieldOption("table.escape", opt))
+	}
+
+	for header, name := range d.ColumnRenames {
+		if name == "" {
+			return nil, parserFieldError("table.column_renames", fmt.Errorf("empty name for %s", header))
+		}
+		options = append(options, ColumnRename(header, name))
+	}
+
+	return options, nil
+}
+
+// options validates the definition and builds the key value options
+func (d *KeyValueDefinition) options() (options []ParserOpt, err error) {
+	if d.Value == nil {
+		return nil, parserFieldError("key_value.value", fmt.Errorf("value is required"))
+	}
+
+	vd := *d.Value
+	if vd.Name == "" {
+		vd.Name = "value"
+	}
+
+	v, err := vd.value(-1)
+	if err != nil {
+		derr := err.(*DefinitionError)
+		return nil, parserFieldError("key_value.value."+derr.Field, derr.Err)
+	}
+
+	// The key value template is replaced by the validated definition value
+	keyValue := KeyValue(d.Regex, v.valueType)
+	if err = keyValue(&Parser{}); err != nil {
+		return nil, parserFieldError("key_value.regex", err)
+	}
+	options = append(options, func(p *Parser) (err error) {
+		if err = keyValue(p); err != nil {
+			return parserFieldError("key_value.regex", err)
+		}
+		p.keyTemplate = v
+		return nil
+	})
+
+	keyOpts, err := keyOptions("key_value", d.Normalize, d.AllowKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(options, keyOpts...), nil
+}
+
+// keyOptions validates and builds the key normalization and allowed keys options of the given mode field
+func keyOptions(field string, normalize KeyFormat, allowKeys []string) (options []ParserOpt, err error) {
+	if normalize != "" {
+		opt := NormalizeKeys(normalize)
+		if err = opt(&Parser{}); err != nil {
+			return nil, parserFieldError(field+".normalize", err)
+		}
+		options = append(options, fieldOption(field+".normalize", opt))
+	}
+
+	if allowKeys != nil {
+		if len(allowKeys) == 0 {
+			return nil, parserFieldError(field+".allow_keys", fmt.Errorf("empty allowed keys"))
+		}
+		options = append(options, AllowKeys(allowKeys...))
+	}
+
+	return options, nil
+}
+
+// value validates the definition and builds a new Value
+func (d *ValueDefinition) value(index int) (v *Value, err error) {
+	var options []ValueOpt
+
+	if d.Name == "" {
+		return nil, d.fieldError(index, "name", fmt.Errorf("name is required"))
+	}
+
+	if _, err = jsonPath(d.Name); err != nil {
+		return nil, d.fieldError(index, "name", err)
+	}
+
+	// Each option is validated on a probe value to report the field that caused the error
+	probe := &Value{name: d.Name, valueType: d.Type, location: time.UTC, clock: time.Now}
+	add := func(field string, opt ValueOpt) (err error) {
+		if err = opt(probe); err != nil {
+			return d.fieldError(index, field, err)
+		}
+		options = append(options, opt)
+		return nil
+	}
+
+	if d.Round != nil {
+		if *d.Round < 0 {
+			return nil, d.fieldError(index, "round", fmt.Errorf("must not be negative: %d", *d.Round))
+		}
+		if err = add("round", Round(*d.Round)); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.Regex != "" {
+		if err = add("regex", ValueRegex(d.Regex)); err != nil {
+			return nil, err
+		}
+		if probe.regex.NumSubexp() != 1 {
+			return nil, d.fieldError(index, "regex",
+				fmt.Errorf("must have exactly one capture group, has %d", probe.regex.NumSubexp()))
+		}
+	}
+
+	if d.Path != nil {
+		for _, key := range d.Path {
+			if key == "" || key == "[]" {
+				return nil, d.fieldError(index, "path", fmt.Errorf("invalid path key %q", key))
+			}
+		}
+		if err = add("path", Path(d.Path...)); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.FromFormat != "" {
+		if err = add("from_format", FromFormat(d.FromFormat)); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.ToFormat != "" {
+		if err = add("to_format", ToFormat(d.ToFormat)); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.FromFormats != nil {
+		if d.Type != Time {
+			return nil, d.fieldError(index, "from_formats", fmt.Errorf("from_formats is only supported for %s", Time))
+		}
+		if err = add("from_formats", FromFormats(d.FromFormats...)); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.Location != "" {
+		if err = add("location", SourceLocation(d.Location)); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.ToLocation != "" {
+		if err = add("to_location", OutputLocation(d.ToLocation)); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.InferYear {
+		options = append(options, InferYear())
+	}
+
+	if d.ShortClock != "" {
+		if err = add("short_clock", ShortClock(d.ShortClock)); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.Nullable {
+		options = append(options, Nullable())
+	}
+
+	if d.Required {
+		options = append(options, Required())
+	}
+
+	if v, err = NewValue(d.Name, d.Type, options...); err != nil {
+		return nil, d.fieldError(index, "type", err)
+	}
+
+	switch v.valueType {
+	case String, Number, Integer, Bool:
+	case Time:
+		if v.fromFormat == "" && v.layouts == nil {
+			return nil, d.fieldError(index, "from_format", fmt.Errorf("from_format is required for %s", v.valueType))
+		}
+		if _, err = v.convertTime(time.Time{}); err != nil {
+			return nil, d.fieldError(index, "to_format", err)
+		}
+	case Duration:
+		if v.fromFormat != "" {
+			if _, err = time.ParseDuration("1" + v.fromFormat); err != nil {
+				return nil, d.fieldError(index, "from_format", fmt.Errorf("unsupported unit %s", v.fromFormat))
+			}
+		}
+		if _, err = v.convertDuration(0); err != nil {
+			return nil, d.fieldError(index, "to_format", err)
+		}
+	case DigitalUnit:
+		if _, ok := digitalUnits[v.fromFormat]; !ok {
+			return nil, d.fieldError(index, "from_format", fmt.Errorf("unsupported unit %s", v.fromFormat))
+		}
+		if _, ok := digitalUnits[v.toFormat]; !ok {
+			return nil, d.fieldError(index, "to_format", fmt.Errorf("unsupported unit %s", v.toFormat))
+		}
+	default:
+		return nil, d.fieldError(index, "type", fmt.Errorf("unsupported type %q", v.valueType))
+	}
+
+	return v, nil
+}
+
+func (d *ValueDefinition) fieldError(index int, field string, err error) (e *DefinitionError) {
+	return &DefinitionError{Value: d.Name, Index: index, Field: field, Err: err}
+}
+
+func parserFieldError(field string, err error) (e *DefinitionError) {
+	return &DefinitionError{Index: -1, Field: field, Err: err}
+}
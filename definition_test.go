@@ -0,0 +1,179 @@
+package rexon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var (
+	defLineJSON = `{
+	"line_regex": "(\\d+)\\s+(\\d+)\\s+(.*?)\\s+",
+	"trim_spaces": true,
+	"values": [
+		{"name": "maj", "type": "number"},
+		{"name": "min", "type": "number"},
+		{"name": "device", "type": "string"}
+	]
+}`
+
+	defSetYAML = `
+start_tag: message.*
+values:
+  - name: message
+    type: string
+    regex: '\s*message\s*(\w+)\s*'
+  - name: id
+    type: number
+    round: 2
+    regex: 'id\s*([-+]?[0-9]*\.?[0-9]+)\s*'
+  - name: vmm
+    type: string
+    regex: 'vmm\s*(\w+)\s*'
+  - name: cdd
+    type: string
+    regex: 'cdd\s*(\w+)'
+`
+)
+
+func TestLoadParserJSON(t *testing.T) {
+	p, err := LoadParser(strings.NewReader(defLineJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dataLine) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		count++
+	}
+
+	if count != 12 {
+		t.Fatalf("expected 12 results, got %d", count)
+	}
+}
+
+func TestLoadParserYAML(t *testing.T) {
+	p, err := LoadParser(strings.NewReader(defSetYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dataMLine) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		count++
+	}
+
+	if count != 2 {
+		t.Fatalf("expected 2 results, got %d", count)
+	}
+}
+
+func TestLoadParserInvalid(t *testing.T) {
+	tests := []struct {
+		def   string
+		field string
+		value string
+	}{
+		{`{"values": [{"name": "a", "type": "nope"}]}`, "type", "a"},
+		{`{"values": [{"name": "a", "type": "string", "regex": "("}]}`, "regex", "a"},
+		{`{"values": [{"name": "a", "type": "string", "regex": "a(b)(c)"}]}`, "regex", "a"},
+		{`{"values": [{"name": "a", "type": "time", "to_format": "unix"}]}`, "from_format", "a"},
+		{`{"values": [{"name": "a", "type": "duration", "to_format": "days"}]}`, "to_format", "a"},
+		{`{"values": [{"name": "a", "type": "string", "from_formats": ["15:04"]}]}`, "from_formats", "a"},
+		{`{"values": [{"name": "a", "type": "time", "from_format": "%Y-%Q"}]}`, "from_format", "a"},
+		{`{"values": [{"name": "a", "type": "time", "from_format": "%F", "to_format": "%Y 1st %j"}]}`, "to_format", "a"},
+		{`{"values": [{"name": "a", "type": "time", "from_format": "%F", "to_format": "days"}]}`, "to_format", "a"},
+		{`{"values": [{"name": "a", "type": "time", "from_format": "15:04", "location": "Nowhere/City"}]}`, "location", "a"},
+		{`{"values": [{"name": "a", "type": "time", "from_format": "15:04", "to_location": "+25:00"}]}`, "to_location", "a"},
+		{`{"values": [{"name": "a", "type": "digital_unit", "from_format": "xb"}]}`, "from_format", "a"},
+		{`{"values": [{"name": "a", "type": "string"}, {"name": "a", "type": "string"}]}`, "name", "a"},
+		{`{"values": [{"name": "a", "type": "number", "round": -1}]}`, "round", "a"},
+		{`{"values": [{"name": "", "type": "number"}]}`, "name", ""},
+		{`{"values": [{"name": "a..b", "type": "number"}]}`, "name", "a..b"},
+		{`{"values": [{"name": "a", "type": "number", "path": []}]}`, "path", "a"},
+		{`{"values": [{"name": "a", "type": "time", "from_formats": []}]}`, "from_formats", "a"},
+		{`{"values": [{"name": "a", "type": "duration", "short_clock": "ss:ms"}]}`, "short_clock", "a"},
+		{`{"values": [{"name": "a", "type": "string", "regex": "%{NOPE}"}]}`, "regex", "a"},
+		{`{"line_regex": "(", "values": [{"name": "a", "type": "string"}]}`, "line_regex", ""},
+		{`{"skip_tag": "x", "values": [{"name": "a", "type": "string"}]}`, "skip_tag", ""},
+		{`{"values": []}`, "values", ""},
+		{`{"line_regex": "(\\d+)", "values": [{"name": "a", "type": "number"}, {"name": "b", "type": "number"}]}`, "line_regex", ""},
+		{`{"table": {"headerless": true, "column_names": ["a"]}}`, "table", ""},
+	}
+
+	for _, test := range tests {
+		_, err := LoadParser(strings.NewReader(test.def))
+		derr, ok := err.(*DefinitionError)
+		if !ok {
+			t.Fatalf("expected a *DefinitionError for %s, got: %#v", test.def, err)
+		}
+
+		if derr.Field != test.field || derr.Value != test.value {
+			t.Fatalf("expected error on %s/%s, got: %s", test.value, test.field, derr)
+		}
+	}
+
+	if _, err := LoadParser(strings.NewReader(`{"unknown": true}`)); err == nil {
+		t.Fatal("expected error for unknown fields")
+	}
+}
+
+func TestDefinitionErrorFields(t *testing.T) {
+	tests := []struct {
+		def   Definition
+		field string
+	}{
+		{Definition{Table: &TableDefinition{}}, "table"},
+		{Definition{KeyValue: &KeyValueDefinition{}}, "key_value"},
+		{Definition{Logfmt: &LogfmtDefinition{}}, "logfmt"},
+		{Definition{LineRegex: "(.*)"}, "line_regex"},
+		{Definition{}, "values"},
+	}
+
+	for _, test := range tests {
+		if field := test.def.modeField(); field != test.field {
+			t.Fatalf("expected field %s, got: %s", test.field, field)
+		}
+	}
+
+	failing := func(p *Parser) (err error) { return fmt.Errorf("failed") }
+	_, err := NewParser(nil, fieldOption("key_value.regex", failing))
+	derr, ok := err.(*DefinitionError)
+	if !ok || derr.Field != "key_value.regex" {
+		t.Fatalf("expected a *DefinitionError on key_value.regex, got: %#v", err)
+	}
+}
+
+func TestLoadParserTable(t *testing.T) {
+	def := `
+table:
+  column_renames:
+    CMD: command
+values:
+  - name: pid
+    type: integer
+`
+	p, err := LoadParser(strings.NewReader(def))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dataPs) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		count++
+	}
+
+	if count != 3 {
+		t.Fatalf("expected 3 results, got %d", count)
+	}
+}
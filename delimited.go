@@ -0,0 +1,97 @@
+package rexon
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Delimited sets the parser to work in delimited table mode, where columns are separated by
+// the given separator as in CSV or TSV data. Fields can be enclosed in double quotes to contain
+// the separator, and a double quote inside a quoted field is escaped by another double quote.
+// Columns are bound to values by the header names as in the Table mode, or by position for
+// Headerless tables.
+func Delimited(sep string) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		if sep == "" {
+			return fmt.Errorf("empty delimiter")
+		}
+		p.table = true
+		p.delimiter = []byte(sep)
+		return nil
+	}
+}
+
+// DelimiterEscape sets an escape character for delimited fields, so that the next character is
+// taken literally, as the `\:` in `nmcli -t` output.
+func DelimiterEscape(c byte) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		if c == '"' {
+			return fmt.Errorf("invalid escape character: %c", c)
+		}
+		p.escape = c
+		return nil
+	}
+}
+
+// delimitedSplitter splits delimited lines, reusing its buffers between lines
+type delimitedSplitter struct {
+	sep    []byte
+	escape byte
+	buf    []byte
+	ends   []int
+}
+
+// split splits the line into fields. The returned fields are only valid until the next call
+func (s *delimitedSplitter) split(line []byte, fields [][]byte) ([][]byte, error) {
+	var err error
+	var quoted bool
+	start := true
+	s.buf = s.buf[:0]
+	s.ends = s.ends[:0]
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+
+		switch {
+		case s.escape != 0 && c == s.escape && i+1 < len(line):
+			i++
+			s.buf = append(s.buf, line[i])
+
+		case c == '"' && quoted:
+			// Handle escaped double quotes inside quoted fields
+			if i+1 < len(line) && line[i+1] == '"' {
+				s.buf = append(s.buf, '"')
+				i++
+				continue
+			}
+			quoted = false
+
+		case c == '"' && start:
+			quoted = true
+
+		case !quoted && bytes.HasPrefix(line[i:], s.sep):
+			s.ends = append(s.ends, len(s.buf))
+			i += len(s.sep) - 1
+			start = true
+			continue
+
+		default:
+			s.buf = append(s.buf, c)
+		}
+
+		start = false
+	}
+
+	if quoted {
+		err = fmt.Errorf("unterminated quoted field: %s", string(line))
+	}
+	s.ends = append(s.ends, len(s.buf))
+
+	var begin int
+	for _, end := range s.ends {
+		fields = append(fields, s.buf[begin:end:end])
+		begin = end
+	}
+
+	return fields, err
+}
@@ -0,0 +1,88 @@
+package rexon
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParserDelimited(t *testing.T) {
+	data := []byte(`Name,Size,Description
+sda,10GB,"system, boot disk"
+sdb,1.5TB,"the ""data"" disk"
+sdc,,
+`)
+
+	values := []*Value{
+		MustNewValue("size", DigitalUnit, ToFormat("gb"), Nullable())}
+
+	p, err := NewParser(values, Delimited(","))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"name":"sda","size":10,"description":"system, boot disk"}`,
+		`{"name":"sdb","size":1500,"description":"the \"data\" disk"}`,
+		`{"name":"sdc","size":null,"description":""}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), data) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+}
+
+func TestParserDelimitedHeaderless(t *testing.T) {
+	data := []byte(`Wired connection 1:802-3-ethernet:eth0:aa\:bb\:cc
+vpn:vpn::
+`)
+
+	values := []*Value{
+		MustNewValue("name", String),
+		MustNewValue("type", String),
+		MustNewValue("device", String),
+		MustNewValue("mac", String)}
+
+	p, err := NewParser(values, Delimited(":"), DelimiterEscape('\\'), Headerless())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"name":"Wired connection 1","type":"802-3-ethernet","device":"eth0","mac":"aa:bb:cc"}`,
+		`{"name":"vpn","type":"vpn","device":"","mac":""}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), data) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+}
+
+func TestParserDelimitedErrors(t *testing.T) {
+	p, err := NewParser(nil, Delimited("\t"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errors int
+	for d := range p.ParseBytes(context.Background(), []byte("a\tb\n1\t2\t3\n\"1\t2\n")) {
+		errors += len(d.Errors)
+	}
+
+	if errors != 3 {
+		t.Fatalf("expected 3 errors, got %d", errors)
+	}
+}
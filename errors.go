@@ -0,0 +1,68 @@
+package rexon
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoMatch is returned when the input does not match the expected form
+	ErrNoMatch = errors.New("no match")
+	// ErrCaptureCount is returned when the number of captured fields differs from the expected
+	ErrCaptureCount = errors.New("invalid number of captures")
+	// ErrUnsupportedFormat is returned for an unsupported source or destination format
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	// ErrUnknownUnit is returned when the input has an unknown unit
+	ErrUnknownUnit = errors.New("unknown unit")
+	// ErrMissingValue is returned when a record is missing a Required Value
+	ErrMissingValue = errors.New("missing required value")
+	// ErrTypeMismatch is returned when a value cannot be decoded into a struct field type
+	ErrTypeMismatch = errors.New("type mismatch")
+)
+
+// ParseError reports an error parsing the input.
+// Line and Offset are only set when parsing data with a Parser
+type ParseError struct {
+	Value  string    // Value name, if the error belongs to a Value
+	Type   ValueType // Value type, if the error belongs to a Value
+	Line   int       // 1 based line number of the input
+	Offset int64     // Byte offset of the line in the input
+	Input  string    // Offending input
+	Err    error     // Underlying cause
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Value == "":
+		return fmt.Sprintf("error parsing line %d, %s", e.Line, e.Err.Error())
+	case e.Line == 0:
+		return fmt.Sprintf("error parsing %s, %s", e.Value, e.Err.Error())
+	}
+	return fmt.Sprintf("error parsing %s at line %d, %s", e.Value, e.Line, e.Err.Error())
+}
+
+// Unwrap returns the underlying cause
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// lineError sets the name and position of a ParseError or wraps the error into one
+func lineError(err error, name string, line int, offset int64) (perr *ParseError) {
+	if !errors.As(err, &perr) {
+		perr = &ParseError{Err: err}
+	}
+
+	if name != "" {
+		perr.Value = name
+	}
+	perr.Line = line
+	perr.Offset = offset
+	return perr
+}
+
+// rowError is a lineError for a whole line, that is kept as the input
+func rowError(err error, input []byte, line int, offset int64) (perr *ParseError) {
+	perr = lineError(err, "", line, offset)
+	perr.Input = string(input)
+	return perr
+}
@@ -0,0 +1,125 @@
+package rexon
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	values := []*Value{
+		MustNewValue("name", String),
+		MustNewValue("size", DigitalUnit, ToFormat("kb"))}
+
+	p, err := NewParser(values, LineRegex(`^(\w+)\s+(\S+)$`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errs []error
+	for d := range p.ParseBytes(context.Background(), []byte("a 1kb\nb 2xb\n\nc x\n")) {
+		errs = append(errs, d.Errors...)
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got: %v", errs)
+	}
+
+	var perr *ParseError
+	if !errors.As(errs[0], &perr) {
+		t.Fatalf("expected a *ParseError, got: %#v", errs[0])
+	}
+
+	if perr.Value != "size" || perr.Type != DigitalUnit || perr.Line != 2 || perr.Offset != 6 || perr.Input != "2xb" {
+		t.Fatalf("unexpected error fields: %#v", perr)
+	}
+
+	if !errors.Is(errs[0], ErrUnknownUnit) {
+		t.Fatalf("expected ErrUnknownUnit, got: %s", errs[0])
+	}
+
+	if !errors.As(errs[1], &perr) || perr.Line != 4 || perr.Offset != 13 || !errors.Is(perr, ErrNoMatch) {
+		t.Fatalf("unexpected error: %#v", errs[1])
+	}
+
+	if errs[0].Error() != "error parsing size at line 2, unknown unit: x" {
+		t.Fatalf("unexpected error message: %s", errs[0])
+	}
+}
+
+func TestParseErrorSentinels(t *testing.T) {
+	tests := []struct {
+		value *Value
+		input string
+		err   error
+	}{
+		{MustNewValue("a", Number, ValueRegex(`(\d+)-(\d+)`)), "1-2", ErrCaptureCount},
+		{MustNewValue("a", Duration, ToFormat("days")), "1s", ErrUnsupportedFormat},
+		{MustNewValue("a", Duration), "5 weeks", ErrUnknownUnit},
+		{MustNewValue("a", DigitalUnit, ToFormat("xb")), "1kb", ErrUnknownUnit},
+		{MustNewValue("a", DigitalUnit), "kb", ErrNoMatch},
+	}
+
+	for _, test := range tests {
+		_, _, err := test.value.Parse([]byte(test.input))
+		if !errors.Is(err, test.err) {
+			t.Fatalf("expected %s for %s, got: %v", test.err, test.input, err)
+		}
+
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Line != 0 || perr.Value != "a" {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	}
+}
+
+func TestParseErrorRow(t *testing.T) {
+	p, err := NewParser(nil, Table(), ColumnNames("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for d := range p.ParseBytes(context.Background(), []byte("1 2 3\n1 2")) {
+		if d.Errors == nil {
+			continue
+		}
+
+		var perr *ParseError
+		if !errors.As(d.Errors[0], &perr) || perr.Line != 2 || perr.Input != "1 2" || !errors.Is(perr, ErrCaptureCount) {
+			t.Fatalf("unexpected error: %#v", d.Errors[0])
+		}
+		return
+	}
+
+	t.Fatal("expected a row error")
+}
+
+func TestParseErrorRowInput(t *testing.T) {
+	tests := []struct {
+		opts  []ParserOpt
+		data  string
+		input string
+	}{
+		{[]ParserOpt{Table(), ColumnNames("a", "b", "c")}, "1 2 3\n1 2\n", "1 2"},
+		{[]ParserOpt{Delimited(","), Headerless()}, `1,"2`, `1,"2`},
+		{[]ParserOpt{Logfmt()}, `a=1 msg="unterminated`, `a=1 msg="unterminated`},
+	}
+
+	for _, test := range tests {
+		values := []*Value{MustNewValue("a", Number), MustNewValue("b", String)}
+		p, err := NewParser(values, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var errs []error
+		for d := range p.ParseBytes(context.Background(), []byte(test.data)) {
+			errs = append(errs, d.Errors...)
+		}
+
+		var perr *ParseError
+		if len(errs) != 1 || !errors.As(errs[0], &perr) || perr.Input != test.input {
+			t.Fatalf("expected a ParseError with input %q for %q, got: %v", test.input, test.data, errs)
+		}
+	}
+}
@@ -2,8 +2,15 @@ package rexon
 
 import (
 	"context"
+	"errors"
 )
 
+// errStop stops a parse from an iterator
+var errStop = errors.New("stop")
+
+// emitFunc delivers a parse result and reports false if parsing must stop
+type emitFunc func(result Result) (ok bool)
+
 // wrapCtxSend wraps the sending to a channel with a context
 func wrapCtxSend(ctx context.Context, result Result, resultCh chan<- Result) (ok bool) {
 	select {
@@ -2,7 +2,9 @@ package rexon
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 	"unsafe"
 
 	"github.com/buger/jsonparser"
@@ -24,7 +26,114 @@ func jsonHas(data []byte, path ...string) (exists bool) {
 }
 
 func jsonSet(data []byte, value interface{}, path ...string) (d []byte, err error) {
-	buf := make([]byte, 0, 16)
+	buf, err := jsonValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return jsonparser.Set(data, buf, path...)
+}
+
+// jsonHasPath is like jsonHas but reports false for paths containing arrays,
+// as array elements are always appended
+func jsonHasPath(data []byte, path []string) (exists bool) {
+	for _, key := range path {
+		if isArrayKey(key) {
+			return false
+		}
+	}
+	return jsonHas(data, path...)
+}
+
+// jsonSetPath sets the value at the given path. Path keys with the "[]" suffix are arrays.
+// A value for an array path is set in the last array element if it is an object without
+// the remaining path, otherwise it is appended to the array as a new element
+func jsonSetPath(data []byte, value interface{}, path []string) (d []byte, err error) {
+	for i, key := range path {
+		if !isArrayKey(key) {
+			continue
+		}
+
+		keys := make([]string, i+1)
+		copy(keys, path[:i])
+		keys[i] = key[:len(key)-2]
+		rest := path[i+1:]
+
+		array, dataType, _, err := jsonparser.Get(data, keys...)
+		if err != nil && err != jsonparser.KeyPathNotFoundError {
+			return nil, err
+		}
+		if dataType != jsonparser.Array {
+			array = []byte(`[]`)
+		}
+
+		var count, lastStart int
+		var last []byte
+		var lastType jsonparser.ValueType
+		end, err := jsonparser.ArrayEach(array, func(v []byte, t jsonparser.ValueType, offset int, _ error) {
+			count++
+			last, lastType, lastStart = v, t, offset
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var elem []byte
+		if len(rest) > 0 && count > 0 && lastType == jsonparser.Object && !jsonHasPath(last, rest) {
+			if elem, err = jsonSetPath(append([]byte(nil), last...), value, rest); err != nil {
+				return nil, err
+			}
+
+			buf := make([]byte, 0, len(array)+len(elem))
+			buf = append(buf, array[:lastStart]...)
+			buf = append(buf, elem...)
+			buf = append(buf, array[lastStart+len(last):]...)
+			return jsonparser.Set(data, buf, keys...)
+		}
+
+		if len(rest) > 0 {
+			elem, err = jsonSetPath(newJSON(), value, rest)
+		} else {
+			elem, err = jsonValue(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, 0, len(array)+len(elem)+1)
+		buf = append(buf, array[:end]...)
+		if count > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, elem...)
+		buf = append(buf, array[end:]...)
+		return jsonparser.Set(data, buf, keys...)
+	}
+
+	return jsonSet(data, value, path...)
+}
+
+// jsonPath splits a dotted name into a json path
+func jsonPath(name string) (path []string, err error) {
+	path = strings.Split(name, ".")
+	if len(path) == 1 {
+		return path, nil
+	}
+
+	for _, key := range path {
+		if key == "" || key == "[]" {
+			return nil, fmt.Errorf("invalid path: %s", name)
+		}
+	}
+	return path, nil
+}
+
+func isArrayKey(key string) (ok bool) {
+	return strings.HasSuffix(key, "[]")
+}
+
+// jsonValue encodes the given value as json
+func jsonValue(value interface{}) (buf []byte, err error) {
+	buf = make([]byte, 0, 16)
 
 	switch v := value.(type) {
 	case nil:
@@ -63,5 +172,5 @@ func jsonSet(data []byte, value interface{}, path ...string) (d []byte, err erro
 			return nil, err
 		}
 	}
-	return jsonparser.Set(data, buf, path...)
+	return buf, nil
 }
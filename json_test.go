@@ -0,0 +1,50 @@
+package rexon
+
+import (
+	"testing"
+)
+
+func TestJSONSetPath(t *testing.T) {
+	sets := []struct {
+		path  []string
+		value interface{}
+	}{
+		{[]string{"host"}, "a"},
+		{[]string{"io", "read", "bytes"}, 10},
+		{[]string{"io", "write", "bytes"}, 20},
+		{[]string{"disks[]", "name"}, "sda"},
+		{[]string{"disks[]", "size"}, 100},
+		{[]string{"disks[]", "name"}, "sdb"},
+		{[]string{"disks[]", "parts[]"}, "sdb1"},
+		{[]string{"disks[]", "parts[]"}, "sdb2"},
+		{[]string{"tags[]"}, "x"},
+		{[]string{"tags[]"}, "y"},
+	}
+
+	var err error
+	data := newJSON()
+	for _, set := range sets {
+		if data, err = jsonSetPath(data, set.value, set.path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := `{"host":"a","io":{"read":{"bytes":10},"write":{"bytes":20}},` +
+		`"disks":[{"name":"sda","size":100},{"name":"sdb","parts":["sdb1","sdb2"]}],"tags":["x","y"]}`
+	if string(data) != expected {
+		t.Fatalf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	path, err := jsonPath("io.read.bytes")
+	if err != nil || len(path) != 3 {
+		t.Fatal(path, err)
+	}
+
+	for _, name := range []string{"io..bytes", ".io", "io.", "io.[]"} {
+		if _, err = jsonPath(name); err == nil {
+			t.Fatalf("expected error for %s", name)
+		}
+	}
+}
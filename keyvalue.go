@@ -0,0 +1,168 @@
+package rexon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyFormat is the normalization applied to KeyValue and Logfmt keys
+type KeyFormat string
+
+const (
+	// KeySnakeCase normalizes keys to snake case: MemTotal, Active(anon) and net.ipv4.ip_forward
+	// become mem_total, active_anon and net_ipv4_ip_forward
+	KeySnakeCase KeyFormat = "snake_case"
+	// KeyLowerCase normalizes keys to lower case
+	KeyLowerCase KeyFormat = "lower_case"
+)
+
+// KeyValue sets the parser to take the document keys from the input. For each line matching the regexp,
+// the first capture group is the key and the second is the value, or the groups named `key` and `value`.
+// Values are parsed with the given type and options, unless a Value without regexp has the same name
+// as the key. Without a StartTag the whole data is parsed into a single document and the first value
+// for a key is kept. Value regexps can still be used to extract other fields.
+func KeyValue(expr string, vt ValueType, options ...ValueOpt) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		if p.keyValue, err = compileRegex(expr); err != nil {
+			return err
+		}
+
+		p.keyGroups = [2]int{1, 2}
+		for i, name := range p.keyValue.SubexpNames() {
+			switch name {
+			case "key":
+				p.keyGroups[0] = i
+			case "value":
+				p.keyGroups[1] = i
+			}
+		}
+
+		if p.keyValue.NumSubexp() < 2 || p.keyGroups[0] == p.keyGroups[1] {
+			return fmt.Errorf("key value regexp must have a key and a value capture group: %s", expr)
+		}
+
+		p.keyTemplate, err = NewValue("value", vt, options...)
+		return err
+	}
+}
+
+// NormalizeKeys sets the normalization for KeyValue and Logfmt keys
+func NormalizeKeys(format KeyFormat) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		switch format {
+		case KeySnakeCase, KeyLowerCase:
+			p.keyFormat = format
+			return nil
+		}
+		return fmt.Errorf("unsupported key format %s", format)
+	}
+}
+
+// AllowKeys restricts the KeyValue and Logfmt keys to the given ones.
+// Keys are matched both before and after normalization
+func AllowKeys(keys ...string) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		if len(keys) == 0 {
+			return fmt.Errorf("empty allowed keys")
+		}
+
+		p.allowKeys = make(map[string]bool, len(keys))
+		for _, key := range keys {
+			p.allowKeys[key] = true
+		}
+		return nil
+	}
+}
+
+// parseKeyValue parses the line key and value into the result.
+// Reports whether the line matched the key value regexp
+func (p *Parser) parseKeyValue(line []byte, result Result, scanner *lineScanner) (Result, bool) {
+	match := p.keyValue.FindSubmatch(line)
+	if match == nil || len(match[p.keyGroups[0]]) == 0 {
+		return result, false
+	}
+
+	key, ok := p.key(match[p.keyGroups[0]])
+	if !ok {
+		return result, true
+	}
+
+	// Keep the first value for each key
+	if jsonHas(result.Data, key) {
+		return result, true
+	}
+
+	v, ok := p.value(key)
+	if !ok || v.regex != nil {
+		v = p.keyTemplate
+	}
+
+	value, _, err := v.Parse(match[p.keyGroups[1]])
+	if err != nil {
+		result.Errors = append(result.Errors, lineError(err, key, scanner.Line(), scanner.Offset()))
+		return result, true
+	}
+
+	result.Data, _ = jsonSet(result.Data, value, key)
+	p.keep(&result, v, value, []string{key})
+	return result, true
+}
+
+// key normalizes the raw key and reports whether it is allowed
+func (p *Parser) key(raw []byte) (key string, ok bool) {
+	key = normalizeKey(string(raw), p.keyFormat)
+	if key == "" {
+		return "", false
+	}
+
+	if p.allowKeys != nil && !p.allowKeys[string(raw)] && !p.allowKeys[key] {
+		return "", false
+	}
+	return key, true
+}
+
+// normalizeKey applies the key format to the key
+func normalizeKey(key string, format KeyFormat) (name string) {
+	switch format {
+	case KeyLowerCase:
+		return strings.ToLower(key)
+	case KeySnakeCase:
+		return snakeCase(key)
+	}
+	return key
+}
+
+// snakeCase converts the key to lower case words separated by underscores,
+// where words are split on case changes and non alphanumeric characters
+func snakeCase(key string) (name string) {
+	buf := make([]byte, 0, len(key)+4)
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'A' && c <= 'Z':
+			// Split on lower to upper case changes and
+			// before the last upper case letter of an acronym
+			if i > 0 && len(buf) > 0 && buf[len(buf)-1] != '_' {
+				prev := key[i-1]
+				next := byte(0)
+				if i+1 < len(key) {
+					next = key[i+1]
+				}
+				if (prev >= 'a' && prev <= 'z') || (prev >= '0' && prev <= '9') ||
+					(prev >= 'A' && prev <= 'Z' && next >= 'a' && next <= 'z') {
+					buf = append(buf, '_')
+				}
+			}
+			buf = append(buf, c+'a'-'A')
+		case (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'):
+			buf = append(buf, c)
+		default:
+			if len(buf) > 0 && buf[len(buf)-1] != '_' {
+				buf = append(buf, '_')
+			}
+		}
+	}
+
+	return strings.TrimRight(string(buf), "_")
+}
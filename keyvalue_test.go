@@ -0,0 +1,127 @@
+package rexon
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+var (
+	dataMeminfo = []byte(`MemTotal:       16318412 kB
+MemFree:         8112300 kB
+Active(anon):    2100404 kB
+HugePages_Total:       0
+Hugepagesize:       2048 kB
+MemTotal:              1 kB`)
+
+	dataSysctl = []byte(`kernel.hostname = myhost
+net.ipv4.ip_forward = 1
+vm.swappiness = 60`)
+)
+
+func TestParserKeyValue(t *testing.T) {
+	values := []*Value{
+		MustNewValue("huge_pages_total", Integer)}
+
+	p, err := NewParser(values,
+		KeyValue(`^([^:]+):\s+(.*)$`, DigitalUnit, ToFormat("mb"), Round(0)),
+		NormalizeKeys(KeySnakeCase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"mem_total":16318,"mem_free":8112,"active_anon":2100,"huge_pages_total":0,"hugepagesize":2}`
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dataMeminfo) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected {
+			t.Fatalf("expected %s, got %s", expected, d.Data)
+		}
+		count++
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 result, got %d", count)
+	}
+}
+
+func TestParserKeyValueAllowKeys(t *testing.T) {
+	p, err := NewParser(nil,
+		KeyValue(`^(?P<key>\S+)\s*=\s*(?P<value>.*)$`, String),
+		NormalizeKeys(KeySnakeCase), AllowKeys("kernel.hostname", "vm_swappiness"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"kernel_hostname":"myhost","vm_swappiness":"60"}`
+	for d := range p.ParseBytes(context.Background(), dataSysctl) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected {
+			t.Fatalf("expected %s, got %s", expected, d.Data)
+		}
+	}
+}
+
+func TestParserKeyValueInvalid(t *testing.T) {
+	if _, err := NewParser(nil, KeyValue(`^(\S+)\s+\S+$`, String)); err == nil {
+		t.Fatal("expected error for a single capture group")
+	}
+
+	if _, err := NewParser(nil, KeyValue(`(\S+) (\S+)`, String), Table()); err == nil {
+		t.Fatal("expected error for table mode")
+	}
+
+	if _, err := NewParser(nil, NormalizeKeys("upper")); err == nil {
+		t.Fatal("expected error for unsupported key format")
+	}
+}
+
+func TestLoadParserKeyValue(t *testing.T) {
+	def := `
+key_value:
+  regex: '^(\S+)\s*=\s*(.*)$'
+  normalize: lower_case
+  value:
+    type: string
+values:
+  - name: swappiness
+    type: integer
+    regex: '^vm\.swappiness\s*=\s*(\d+)'
+`
+	p, err := LoadParser(strings.NewReader(def))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"kernel.hostname":"myhost","net.ipv4.ip_forward":"1","vm.swappiness":"60","swappiness":60}`
+	for d := range p.ParseBytes(context.Background(), dataSysctl) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected {
+			t.Fatalf("expected %s, got %s", expected, d.Data)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"MemTotal":            "mem_total",
+		"Active(anon)":        "active_anon",
+		"HTTPServer":          "http_server",
+		"DirectMap4k":         "direct_map4k",
+		"net.ipv4.ip_forward": "net_ipv4_ip_forward",
+		"already_snake":       "already_snake",
+	}
+
+	for key, expected := range tests {
+		if name := snakeCase(key); name != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, key, name)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package rexon
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// AutoFormat is the Time FromFormat that recognizes the common time layouts
+	AutoFormat = "auto"
+
+	// layoutEpoch parses unix epoch numbers in seconds, milliseconds, microseconds or nanoseconds
+	layoutEpoch = "epoch_auto"
+	// layoutUnix parses unix epoch numbers in seconds
+	layoutUnix = "unix"
+	// layoutUnixMilli parses unix epoch numbers in milliseconds
+	layoutUnixMilli = "unix_milli"
+	// layoutUnixMicro parses unix epoch numbers in microseconds
+	layoutUnixMicro = "unix_micro"
+	// layoutUnixNano parses unix epoch numbers in nanoseconds
+	layoutUnixNano = "unix_nano"
+	// layoutISOWeek parses ISO 8601 week dates as 2006-W01-1
+	layoutISOWeek = "iso_week"
+)
+
+var (
+	rexISOWeek = regexp.MustCompile(`^(\d{4})-?W(\d{2})(?:-?([1-7]))?$`)
+
+	// autoLayouts are the layouts tried by the AutoFormat
+	autoLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		time.RFC1123,
+		time.RFC1123Z,
+		time.RFC850,
+		time.RFC822,
+		time.RFC822Z,
+		time.ANSIC,
+		time.UnixDate,
+		time.RubyDate,
+		time.Stamp,
+		"02/Jan/2006:15:04:05 -0700",
+		"01/02/2006 15:04:05",
+		"01/02/2006 15:04",
+		"01/02/2006",
+		layoutISOWeek,
+		layoutEpoch,
+	}
+)
+
+// FromFormats sets the layouts tried in order to parse a Time value, as Go layouts or strftime formats.
+// The layout that matched last is tried first in the following values
+func FromFormats(layouts ...string) (opt ValueOpt) {
+	return func(v *Value) (err error) {
+		if len(layouts) == 0 {
+			return fmt.Errorf("empty time layouts for %s", v.name)
+		}
+
+		v.layouts = make([]string, len(layouts))
+		for i := range layouts {
+			if v.layouts[i], err = timeLayout(layouts[i], false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// parseLayouts parses the time trying first the last matched layout and then the others in order
+func (v *Value) parseLayouts(layouts []string, s string) (t time.Time, err error) {
+	last := int(v.layout.Load())
+	if last >= len(layouts) {
+		last = 0
+	}
+
+	if t, err = parseLayout(layouts[last], s, v.location); err == nil {
+		return t, nil
+	}
+
+	for i, layout := range layouts {
+		if i == last {
+			continue
+		}
+
+		if t, err = parseLayout(layout, s, v.location); err == nil {
+			v.layout.Store(int32(i))
+			return t, nil
+		}
+	}
+
+	return t, fmt.Errorf("%w for time layouts: %s", ErrNoMatch, s)
+}
+
+// parseLayout parses the time with the given layout
+func parseLayout(layout, s string, loc *time.Location) (t time.Time, err error) {
+	switch layout {
+	case layoutEpoch:
+		return parseEpoch(s, loc)
+	case layoutUnix:
+		return parseUnix(s, time.Second, loc)
+	case layoutUnixMilli:
+		return parseUnix(s, time.Millisecond, loc)
+	case layoutUnixMicro:
+		return parseUnix(s, time.Microsecond, loc)
+	case layoutUnixNano:
+		return parseUnix(s, time.Nanosecond, loc)
+	case layoutISOWeek:
+		return parseISOWeek(s, loc)
+	}
+	return time.ParseInLocation(layout, s, loc)
+}
+
+// parseEpoch parses a unix epoch of at least 9 digits guessing the unit from its magnitude
+func parseEpoch(s string, loc *time.Location) (t time.Time, err error) {
+	integer := s
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer = s[:i]
+	}
+
+	n, err := strconv.ParseInt(integer, 10, 64)
+	if err != nil || n < 0 || len(integer) < 9 {
+		return t, fmt.Errorf("invalid epoch: %s", s)
+	}
+
+	switch {
+	case n < 1e11:
+		return parseUnix(s, time.Second, loc)
+	case n < 1e14:
+		return parseUnix(s, time.Millisecond, loc)
+	case n < 1e17:
+		return parseUnix(s, time.Microsecond, loc)
+	}
+	return parseUnix(s, time.Nanosecond, loc)
+}
+
+// parseUnix parses a unix epoch in the given unit with an optional fractional part
+func parseUnix(s string, unit time.Duration, loc *time.Location) (t time.Time, err error) {
+	integer, fraction := s, ""
+	i := strings.IndexByte(s, '.')
+	if i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+
+	n, err := strconv.ParseInt(integer, 10, 64)
+	if err != nil || len(fraction) > 9 || (i >= 0 && fraction == "") {
+		return t, fmt.Errorf("invalid epoch: %s", s)
+	}
+
+	// Fraction of the unit in nanoseconds
+	var nsec int64
+	for i := 0; i < 9; i++ {
+		nsec *= 10
+		if i >= len(fraction) {
+			continue
+		}
+		if fraction[i] < '0' || fraction[i] > '9' {
+			return t, fmt.Errorf("invalid epoch: %s", s)
+		}
+		nsec += int64(fraction[i] - '0')
+	}
+	nsec = nsec * int64(unit) / int64(time.Second)
+	if strings.HasPrefix(integer, "-") {
+		nsec = -nsec
+	}
+
+	perSecond := int64(time.Second / unit)
+	t = time.Unix(n/perSecond, n%perSecond*int64(unit)+nsec)
+	return t.In(loc), nil
+}
+
+// parseISOWeek parses an ISO 8601 week date in the 2006-W01-1 or 2006W011 forms,
+// where the day of the week defaults to Monday
+func parseISOWeek(s string, loc *time.Location) (t time.Time, err error) {
+	m := rexISOWeek.FindStringSubmatch(s)
+	if m == nil {
+		return t, fmt.Errorf("invalid iso week date: %s", s)
+	}
+
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+	day := 1
+	if m[3] != "" {
+		day, _ = strconv.Atoi(m[3])
+	}
+
+	// January 4th is always in the first week
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	t = monday.AddDate(0, 0, (week-1)*7+day-1)
+
+	if y, w := t.ISOWeek(); week < 1 || y != year || w != week {
+		return time.Time{}, fmt.Errorf("invalid iso week date: %s", s)
+	}
+	return t, nil
+}
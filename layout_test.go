@@ -0,0 +1,182 @@
+package rexon
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValueParseTimeLayouts(t *testing.T) {
+	v, err := NewValue("time", Time, ToFormat("rfc3339"),
+		FromFormats("2006-01-02 15:04:05", "01/02/2006 15:04", time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+		layout   int32
+	}{
+		{"2018-12-25 15:04:05", "2018-12-25T15:04:05Z", 0},
+		{"12/25/2018 15:04", "2018-12-25T15:04:00Z", 1},
+		{"12/26/2018 16:04", "2018-12-26T16:04:00Z", 1},
+		{"2018-12-25T15:04:05-02:00", "2018-12-25T15:04:05-02:00", 2},
+		{"2018-12-25 15:04:05", "2018-12-25T15:04:05Z", 0},
+	}
+
+	for _, test := range tests {
+		value, _, err := v.Parse([]byte(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Fatalf("expected %s for %s, got: %v", test.expected, test.input, value)
+		}
+		if layout := v.layout.Load(); layout != test.layout {
+			t.Fatalf("expected layout %d for %s, got: %d", test.layout, test.input, layout)
+		}
+	}
+
+	_, _, err = v.Parse([]byte("Dec 25 15:04:05"))
+	if !errors.Is(err, ErrNoMatch) {
+		t.Fatalf("expected %s, got: %v", ErrNoMatch, err)
+	}
+}
+
+func TestValueParseTimeAuto(t *testing.T) {
+	v, err := NewValue("time", Time, FromFormat(AutoFormat), ToFormat("rfc3339nano"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2018-12-25T15:04:05Z", "2018-12-25T15:04:05Z"},
+		{"2018-12-25T15:04:05.123-02:00", "2018-12-25T15:04:05.123-02:00"},
+		{"2018-12-25 15:04:05", "2018-12-25T15:04:05Z"},
+		{"Tue, 25 Dec 2018 15:04:05 UTC", "2018-12-25T15:04:05Z"},
+		{"Tue Dec 25 15:04:05 2018", "2018-12-25T15:04:05Z"},
+		{"25/Dec/2018:15:04:05 +0100", "2018-12-25T15:04:05+01:00"},
+		{"12/25/2018 15:04", "2018-12-25T15:04:00Z"},
+		{"2018-W52-2", "2018-12-25T00:00:00Z"},
+		{"2020W531", "2020-12-28T00:00:00Z"},
+		{"1545750245", "2018-12-25T15:04:05Z"},
+		{"1545750245.5", "2018-12-25T15:04:05.5Z"},
+		{"1545750245123", "2018-12-25T15:04:05.123Z"},
+		{"1545750245123456", "2018-12-25T15:04:05.123456Z"},
+		{"1545750245123456789", "2018-12-25T15:04:05.123456789Z"},
+		{"Dec 25 15:04:05", "0000-12-25T15:04:05Z"},
+	}
+
+	for _, test := range tests {
+		value, _, err := v.Parse([]byte(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Fatalf("expected %s for %s, got: %v", test.expected, test.input, value)
+		}
+	}
+
+	for _, input := range []string{"2018-W53-1", "2018-W00-1", "12345", "not a time"} {
+		if _, _, err = v.Parse([]byte(input)); err == nil {
+			t.Fatalf("expected error for %s", input)
+		}
+	}
+
+	v, err = NewValue("time", Time, FromFormat(AutoFormat), ToFormat("unix"), InferYear(),
+		Clock(func() time.Time { return time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, _, err := v.Parse([]byte("Dec 25 15:04:05"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != int64(1545750245) {
+		t.Fatalf("expected 1545750245, got: %v", value)
+	}
+}
+
+func BenchmarkValueParseTimeAuto(b *testing.B) {
+	v, err := NewValue("time", Time, FromFormat(AutoFormat), ToFormat("unix"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	data := []byte("Tue Dec 25 15:04:05 2018")
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, _, err = v.Parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestValueParseTimeEpoch(t *testing.T) {
+	tests := []struct {
+		from     string
+		to       string
+		input    string
+		expected interface{}
+	}{
+		{"unix", "rfc3339nano", "1697452321", "2023-10-16T10:32:01Z"},
+		{"unix", "rfc3339nano", "1697452321.123", "2023-10-16T10:32:01.123Z"},
+		{"unix", "unix_milli", "-1.5", int64(-1500)},
+		{"unix", "rfc3339", "0", "1970-01-01T00:00:00Z"},
+		{"unix_milli", "unix", "1697452321123", int64(1697452321)},
+		{"unix_milli", "rfc3339nano", "1697452321123.5", "2023-10-16T10:32:01.1235Z"},
+		{"unix_micro", "unix_nano", "1697452321123456", int64(1697452321123456000)},
+		{"unix_nano", "unix_micro", "1697452321123456789", int64(1697452321123456)},
+		{"epoch_auto", "rfc3339nano", "1697452321.123", "2023-10-16T10:32:01.123Z"},
+		{"epoch_auto", "unix_nano", "1697452321123", int64(1697452321123000000)},
+		{"epoch_auto", "unix_nano", "1697452321123456", int64(1697452321123456000)},
+		{"epoch_auto", "unix_nano", "1697452321123456789", int64(1697452321123456789)},
+		{"unix", "%Y-%m-%d %H:%M:%S", "1697452321", "2023-10-16 10:32:01"},
+	}
+
+	for _, test := range tests {
+		v, err := NewValue("time", Time, FromFormat(test.from), ToFormat(test.to))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value, _, err := v.Parse([]byte(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Fatalf("expected %v for %s %s -> %s, got: %v", test.expected, test.from, test.input, test.to, value)
+		}
+	}
+
+	v, err := NewValue("time", Time, FromFormat("unix"), ToFormat("rfc3339"), SourceLocation("-03:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _, err := v.Parse([]byte("1697452321"))
+	if err != nil || value != "2023-10-16T07:32:01-03:00" {
+		t.Fatalf("expected 2023-10-16T07:32:01-03:00, got: %v, %v", value, err)
+	}
+
+	inputs := map[string][]string{
+		"unix":       {"", "abc", "1697452321.", "1697452321.1234567890", "1697452321.12a", ".5"},
+		"epoch_auto": {"12345", "-1697452321", "1697452321.x"},
+	}
+	for from, inputs := range inputs {
+		v, err := NewValue("time", Time, FromFormat(from))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, input := range inputs {
+			if _, _, err = v.Parse([]byte(input)); err == nil {
+				t.Fatalf("expected error for %s %q", from, input)
+			}
+		}
+	}
+}
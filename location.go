@@ -0,0 +1,62 @@
+package rexon
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	rexOffset = regexp.MustCompile(`^(?:UTC|GMT)?([+-])(\d{1,2})(?::?(\d{2}))?$`)
+)
+
+// SourceLocation sets the location of Time values parsed without a zone, defaults to UTC.
+// The location is an IANA name as "America/Sao_Paulo", "Local", "UTC" or a fixed offset as "-03:00".
+// IANA names are loaded from the system zoneinfo, programs that run where it is not available
+// can embed it by importing time/tzdata in their main package or building with the timetzdata tag
+func SourceLocation(name string) (opt ValueOpt) {
+	return func(v *Value) (err error) {
+		v.location, err = loadLocation(name)
+		return err
+	}
+}
+
+// OutputLocation sets the location Time values are converted to before formatting,
+// defaults to the parsed location. The location name is as in SourceLocation
+func OutputLocation(name string) (opt ValueOpt) {
+	return func(v *Value) (err error) {
+		v.toLocation, err = loadLocation(name)
+		return err
+	}
+}
+
+// loadLocation loads the location from the system zoneinfo or the embedded tzdata,
+// or creates a fixed zone for offsets in the [UTC|GMT]+hh[[:]mm] form
+func loadLocation(name string) (loc *time.Location, err error) {
+	if name == "" {
+		return nil, fmt.Errorf("empty location")
+	}
+
+	if m := rexOffset.FindStringSubmatch(name); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		var minutes int
+		if m[3] != "" {
+			minutes, _ = strconv.Atoi(m[3])
+		}
+		if hours > 14 || minutes > 59 {
+			return nil, fmt.Errorf("invalid location offset: %s", name)
+		}
+
+		offset := hours*3600 + minutes*60
+		if m[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(name, offset), nil
+	}
+
+	if loc, err = time.LoadLocation(name); err != nil {
+		return nil, fmt.Errorf("invalid location %s: %w", name, err)
+	}
+	return loc, nil
+}
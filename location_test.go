@@ -0,0 +1,94 @@
+package rexon
+
+import (
+	"testing"
+	"time"
+
+	// Embedded zoneinfo so the tests do not depend on the system database
+	_ "time/tzdata"
+)
+
+func TestLoadLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+	}{
+		{"UTC", 0},
+		{"America/Sao_Paulo", -3 * 3600},
+		{"Asia/Kolkata", 5*3600 + 1800},
+		{"-03:00", -3 * 3600},
+		{"+0530", 5*3600 + 1800},
+		{"UTC+2", 2 * 3600},
+		{"GMT-7", -7 * 3600},
+	}
+
+	at := time.Date(2018, 6, 25, 12, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		loc, err := loadLocation(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, offset := at.In(loc).Zone(); offset != test.offset {
+			t.Fatalf("expected offset %d for %s, got: %d", test.offset, test.name, offset)
+		}
+	}
+
+	for _, name := range []string{"", "Nowhere/City", "+15:00", "+03:60"} {
+		if _, err := loadLocation(name); err == nil {
+			t.Fatalf("expected error for location %q", name)
+		}
+	}
+}
+
+func TestValueParseTimeLocation(t *testing.T) {
+	tests := []struct {
+		opts     []ValueOpt
+		expected string
+	}{
+		{nil, "2018-12-25T15:04:05Z"},
+		{[]ValueOpt{SourceLocation("America/Sao_Paulo")}, "2018-12-25T15:04:05-02:00"},
+		{[]ValueOpt{SourceLocation("America/Sao_Paulo"), OutputLocation("UTC")}, "2018-12-25T17:04:05Z"},
+		{[]ValueOpt{SourceLocation("+05:30"), OutputLocation("Europe/Berlin")}, "2018-12-25T10:34:05+01:00"},
+		{[]ValueOpt{OutputLocation("-03:00")}, "2018-12-25T12:04:05-03:00"},
+	}
+
+	for _, test := range tests {
+		opts := append([]ValueOpt{
+			ToFormat("rfc3339"),
+			FromFormat("2006-01-02 15:04:05"),
+			ValueRegex(`time:\s+(.*)`)}, test.opts...)
+
+		v, err := NewValue("time", Time, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value, ok, err := v.Parse(dataTime)
+		if !ok || err != nil {
+			t.Fatal(ok, err)
+		}
+
+		if value != test.expected {
+			t.Fatalf("expected %s, got: %v", test.expected, value)
+		}
+	}
+
+	v, err := NewValue("time", Time, ToFormat("unix"), FromFormat("2006-01-02 15:04:05"),
+		SourceLocation("America/Sao_Paulo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, _, err := v.Parse([]byte("2018-12-25 15:04:05"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != int64(1545757445) {
+		t.Fatalf("expected 1545757445, got: %v", value)
+	}
+
+	if _, err = NewValue("time", Time, SourceLocation("Nowhere/City")); err == nil {
+		t.Fatal("expected error for an invalid location")
+	}
+}
@@ -0,0 +1,184 @@
+package rexon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Logfmt sets the parser to work in logfmt mode, where each line of `key=value` pairs is parsed into
+// a document. Values can be double quoted with backslash escapes and keys without a value are true.
+// Keys are parsed by the Value with the same name or as strings when there is none.
+func Logfmt() (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.logfmt = true
+		return nil
+	}
+}
+
+func (p *Parser) parseLogfmt(ctx context.Context, data io.Reader, emit emitFunc) {
+	var skip bool
+	var line []byte
+	var result Result
+	var buf []byte
+	scanner := newLineScanner(data)
+	track := p.newTracker()
+
+	for scanner.Scan() {
+		track.read(scanner.Line())
+		line = scanner.Bytes()
+		if p.trimSpaces {
+			line = bytes.TrimSpace(line)
+		}
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		if p.stopTag != nil && p.stopTag.Match(line) {
+			track.stopped(scanner.Line())
+			break
+		}
+
+		if p.skipLine(line, &skip) {
+			track.skipped(scanner.Line())
+			continue
+		}
+
+		result = Result{}
+		result.Data = newJSON()
+		scanner.meta(&result.Meta, line, p.rawText)
+		raw := line
+
+		var matched bool
+		for len(line) > 0 {
+			var key, value []byte
+			var quoted bool
+			var err error
+
+			key, value, quoted, line, buf, err = nextLogfmtPair(line, buf[:0])
+			if err != nil {
+				result.Errors = append(result.Errors, rowError(err, raw, scanner.Line(), scanner.Offset()))
+			}
+
+			if len(key) == 0 {
+				continue
+			}
+			matched = true
+
+			result = p.setLogfmtPair(result, key, value, quoted, scanner)
+		}
+
+		if !matched && result.Errors == nil {
+			if !p.unmatched(ctx, emit, track, scanner, raw) {
+				return
+			}
+			continue
+		}
+		track.matched(scanner.Line())
+
+		if !p.send(ctx, track, result, emit) {
+			return
+		}
+	}
+
+	if !p.sendScanError(ctx, track, scanner, emit) {
+		return
+	}
+
+	p.sendSummary(ctx, emit, track.summary)
+}
+
+// setLogfmtPair parses the pair value into the result
+func (p *Parser) setLogfmtPair(result Result, rawKey, raw []byte, quoted bool, scanner *lineScanner) Result {
+	key, ok := p.key(rawKey)
+	if !ok {
+		return result
+	}
+
+	path := []string{key}
+	v, ok := p.value(key)
+	if ok {
+		path = v.path
+	}
+
+	// Keep the first value for each key
+	if jsonHasPath(result.Data, path) {
+		return result
+	}
+
+	var value interface{}
+	switch {
+	case ok:
+		var err error
+		if value, _, err = v.Parse(raw); err != nil {
+			result.Errors = append(result.Errors, lineError(err, key, scanner.Line(), scanner.Offset()))
+		}
+	case raw == nil && !quoted:
+		value = true
+	default:
+		value = string(raw)
+	}
+
+	p.set(&result, v, value, path)
+	return result
+}
+
+// nextLogfmtPair returns the next pair from the line and the remaining of the line.
+// Unescaped quoted values are stored in buf, raw is nil for keys without a value
+func nextLogfmtPair(line, buf []byte) (key, raw []byte, quoted bool, rest, b []byte, err error) {
+	line = bytes.TrimLeft(line, " \t")
+
+	// Key up to the equal sign or a space
+	end := bytes.IndexAny(line, "= \t")
+	if end < 0 {
+		return line, nil, false, nil, buf, nil
+	}
+	key, line = line[:end], line[end:]
+
+	if line[0] != '=' {
+		return key, nil, false, line, buf, nil
+	}
+	line = line[1:]
+
+	if len(line) == 0 || line[0] != '"' {
+		end = bytes.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		return key, line[:end:end], false, line[end:], buf, nil
+	}
+
+	// Quoted value with backslash escapes
+	for i := 1; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			return key, buf, true, line[i+1:], buf, nil
+		case '\\':
+			if i+1 < len(line) {
+				i++
+				buf = append(buf, logfmtUnescape(line[i])...)
+				continue
+			}
+		}
+		buf = append(buf, line[i])
+	}
+
+	return key, buf, true, nil, buf, fmt.Errorf("unterminated quoted value for key %s", key)
+}
+
+// logfmtUnescape returns the escaped character
+func logfmtUnescape(c byte) (b []byte) {
+	switch c {
+	case 'n':
+		return []byte{'\n'}
+	case 't':
+		return []byte{'\t'}
+	case 'r':
+		return []byte{'\r'}
+	case '"', '\\':
+		return []byte{c}
+	}
+	return []byte{'\\', c}
+}
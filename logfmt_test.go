@@ -0,0 +1,123 @@
+package rexon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var dataLogfmt = []byte(`level=info msg="request done" dur=12ms bytes=1.2KB
+level=warn msg="quote \"here\"\tand tab" path=/api/v1 retry
+
+level=error msg= dur=1s empty=""`)
+
+func TestParserLogfmt(t *testing.T) {
+	values := []*Value{
+		MustNewValue("dur", Duration, ToFormat("ms")),
+		MustNewValue("bytes", DigitalUnit, ToFormat("b"))}
+
+	p, err := NewParser(values, Logfmt())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"level":"info","msg":"request done","dur":12,"bytes":1200}`,
+		`{"level":"warn","msg":"quote \"here\"\tand tab","path":"/api/v1","retry":true}`,
+		`{"level":"error","msg":"","dur":1000,"empty":""}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dataLogfmt) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+
+	if count != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), count)
+	}
+}
+
+func TestParserLogfmtErrors(t *testing.T) {
+	values := []*Value{
+		MustNewValue("dur", Duration)}
+
+	p, err := NewParser(values, Logfmt())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), []byte(`dur=abc msg="unterminated`)) {
+		if len(d.Errors) != 2 {
+			t.Fatalf("expected 2 errors, got: %v", d.Errors)
+		}
+		if string(d.Data) != `{"dur":null,"msg":"unterminated"}` {
+			t.Fatalf("unexpected document: %s", d.Data)
+		}
+		count++
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 result, got %d", count)
+	}
+
+	if _, err = NewParser(nil, Logfmt(), Table()); err == nil {
+		t.Fatal("expected error for logfmt with table mode")
+	}
+}
+
+func TestLoadParserLogfmt(t *testing.T) {
+	def := `
+logfmt: {}
+values:
+  - name: dur
+    type: duration
+    to_format: ms
+`
+	p, err := LoadParser(strings.NewReader(def))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dataLogfmt) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		count++
+	}
+
+	if count != 3 {
+		t.Fatalf("expected 3 results, got %d", count)
+	}
+
+	def = `
+logfmt:
+  normalize: snake_case
+  allow_keys: [Level, msg]
+`
+	if p, err = LoadParser(strings.NewReader(def)); err != nil {
+		t.Fatal(err)
+	}
+
+	var docs []string
+	for d := range p.ParseBytes(context.Background(), []byte("Level=info msg=done dur=1s\n")) {
+		docs = append(docs, string(d.Data))
+	}
+	if len(docs) != 1 || docs[0] != `{"level":"info","msg":"done"}` {
+		t.Fatalf("expected normalized and allowed keys, got: %v", docs)
+	}
+
+	_, err = LoadParser(strings.NewReader("logfmt:\n  normalize: camel\n"))
+	var derr *DefinitionError
+	if !errors.As(err, &derr) || derr.Field != "logfmt.normalize" {
+		t.Fatalf("expected a logfmt.normalize definition error, got: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package rexon
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Observer is notified of the parse events with the 1 based line number.
+// Observers are shared by all the parses of a Parser and must be safe for concurrent use.
+type Observer interface {
+	LineRead(line int)            // A line was read
+	LineSkipped(line int)         // A line was skipped between SkipTag and ContinueTag
+	LineMatched(line int)         // A line was matched by the parser
+	LineUnmatched(line int)       // A line was not matched by the parser
+	StopTag(line int)             // The StopTag matched and the parse stopped
+	ValueError(err *ParseError)   // A Value could not be parsed or a Required Value is missing
+	RecordEmitted(result *Result) // A record was sent in the results
+}
+
+// Stats holds the parse event counters of a Parser
+type Stats struct {
+	LinesRead      int64
+	LinesSkipped   int64
+	LinesMatched   int64
+	LinesUnmatched int64
+	StopTags       int64
+	ValueErrors    int64
+	Records        int64
+}
+
+// Observe adds an Observer to the parser
+func Observe(observer Observer) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		if observer == nil {
+			return fmt.Errorf("nil observer")
+		}
+		p.observers = append(p.observers, observer)
+		return nil
+	}
+}
+
+// Stats returns the event counters of all the parses done with the parser
+func (p *Parser) Stats() (stats Stats) {
+	return p.counters.Stats()
+}
+
+// counters is the built-in Observer counting the parse events
+type counters struct {
+	linesRead      atomic.Int64
+	linesSkipped   atomic.Int64
+	linesMatched   atomic.Int64
+	linesUnmatched atomic.Int64
+	stopTags       atomic.Int64
+	valueErrors    atomic.Int64
+	records        atomic.Int64
+}
+
+func (c *counters) LineRead(line int)            { c.linesRead.Add(1) }
+func (c *counters) LineSkipped(line int)         { c.linesSkipped.Add(1) }
+func (c *counters) LineMatched(line int)         { c.linesMatched.Add(1) }
+func (c *counters) LineUnmatched(line int)       { c.linesUnmatched.Add(1) }
+func (c *counters) StopTag(line int)             { c.stopTags.Add(1) }
+func (c *counters) ValueError(err *ParseError)   { c.valueErrors.Add(1) }
+func (c *counters) RecordEmitted(result *Result) { c.records.Add(1) }
+
+// Stats returns a snapshot of the counters
+func (c *counters) Stats() (stats Stats) {
+	return Stats{
+		LinesRead:      c.linesRead.Load(),
+		LinesSkipped:   c.linesSkipped.Load(),
+		LinesMatched:   c.linesMatched.Load(),
+		LinesUnmatched: c.linesUnmatched.Load(),
+		StopTags:       c.stopTags.Load(),
+		ValueErrors:    c.valueErrors.Load(),
+		Records:        c.records.Load(),
+	}
+}
+
+// tracker counts the lines of a single parse and notifies the parser observers
+type tracker struct {
+	summary   Summary
+	counters  *counters
+	observers []Observer
+}
+
+func (p *Parser) newTracker() (t *tracker) {
+	return &tracker{counters: p.counters, observers: p.observers}
+}
+
+func (t *tracker) read(line int) {
+	t.summary.Lines++
+	t.counters.LineRead(line)
+	for _, o := range t.observers {
+		o.LineRead(line)
+	}
+}
+
+func (t *tracker) skipped(line int) {
+	t.summary.Skipped++
+	t.counters.LineSkipped(line)
+	for _, o := range t.observers {
+		o.LineSkipped(line)
+	}
+}
+
+func (t *tracker) matched(line int) {
+	t.summary.Matched++
+	t.counters.LineMatched(line)
+	for _, o := range t.observers {
+		o.LineMatched(line)
+	}
+}
+
+func (t *tracker) unmatched(line int) {
+	t.summary.Unmatched++
+	t.counters.LineUnmatched(line)
+	for _, o := range t.observers {
+		o.LineUnmatched(line)
+	}
+}
+
+func (t *tracker) stopped(line int) {
+	t.counters.StopTag(line)
+	for _, o := range t.observers {
+		o.StopTag(line)
+	}
+}
+
+// failed notifies the Value errors
+func (t *tracker) failed(err error) {
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Value == "" {
+		return
+	}
+
+	t.counters.ValueError(perr)
+	for _, o := range t.observers {
+		o.ValueError(perr)
+	}
+}
+
+// emit sends the result and notifies the records sent
+func (t *tracker) emit(result Result, emit emitFunc) (ok bool) {
+	if !emit(result) {
+		return false
+	}
+
+	if result.Data != nil {
+		t.counters.RecordEmitted(&result)
+		for _, o := range t.observers {
+			o.RecordEmitted(&result)
+		}
+	}
+	return true
+}
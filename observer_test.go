@@ -0,0 +1,69 @@
+package rexon
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type testObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *testObserver) add(event string) {
+	o.mu.Lock()
+	o.events = append(o.events, event)
+	o.mu.Unlock()
+}
+
+func (o *testObserver) LineRead(line int)            { o.add("read") }
+func (o *testObserver) LineSkipped(line int)         { o.add("skipped") }
+func (o *testObserver) LineMatched(line int)         { o.add("matched") }
+func (o *testObserver) LineUnmatched(line int)       { o.add("unmatched") }
+func (o *testObserver) StopTag(line int)             { o.add("stop") }
+func (o *testObserver) ValueError(err *ParseError)   { o.add("error:" + err.Value) }
+func (o *testObserver) RecordEmitted(result *Result) { o.add("record") }
+
+func TestParserObserver(t *testing.T) {
+	values := []*Value{
+		MustNewValue("id", Integer)}
+
+	observer := &testObserver{}
+	p, err := NewParser(values, LineRegex(`^id (\S+)`), SkipTag(`^# skip`), ContinueTag(`^# continue`),
+		StopTag(`^end`), Observe(observer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("id 1\n# skip\nid 2\n# continue\nid x\nend\nid 3\n")
+	for i := 0; i < 2; i++ {
+		for range p.ParseBytes(context.Background(), data) {
+		}
+	}
+
+	expected := []string{
+		"read", "matched", "record",
+		"read", "skipped",
+		"read", "skipped",
+		"read", "unmatched",
+		"read", "matched", "error:id", "record",
+		"read", "stop",
+	}
+
+	if len(observer.events) != 2*len(expected) {
+		t.Fatalf("expected %d events, got %v", 2*len(expected), observer.events)
+	}
+
+	for i := range expected {
+		if observer.events[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, observer.events[:len(expected)])
+		}
+	}
+
+	stats := Stats{LinesRead: 12, LinesSkipped: 4, LinesMatched: 4, LinesUnmatched: 2,
+		StopTags: 2, ValueErrors: 2, Records: 4}
+	if p.Stats() != stats {
+		t.Fatalf("expected %#v, got %#v", stats, p.Stats())
+	}
+}
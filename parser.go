@@ -1,34 +1,61 @@
 package rexon
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"iter"
 	"regexp"
 	"strings"
 )
 
 var (
-	rexDefaultStartTag      = regexp.MustCompile(`.`)
-	errInvalidParsersNumber = fmt.Errorf("invalid number of matches and parsers")
+	rexDefaultStartTag = regexp.MustCompile(`.`)
 )
 
 // make sure we satisfy the rexon.ParserInterface
 var _ DataParser = (*Parser)(nil)
 
-// Parser type
+// Parser extracts JSON documents from data. A Parser is immutable after NewParser and safe for
+// concurrent use, as the state of each parse is kept by the Parse invocation
 type Parser struct {
-	findAll     bool
-	multiLine   bool
-	trimSpaces  bool
-	startTag    *regexp.Regexp
-	stopTag     *regexp.Regexp
-	skipTag     *regexp.Regexp
-	continueTag *regexp.Regexp
-	regex       *regexp.Regexp
-	values      []*Value
+	findAll         bool
+	multiLine       bool
+	trimSpaces      bool
+	startTag        *regexp.Regexp
+	stopTag         *regexp.Regexp
+	skipTag         *regexp.Regexp
+	continueTag     *regexp.Regexp
+	regex           *regexp.Regexp
+	groups          []int // LineRegex capture group index for each value
+	patternFields   []patternField
+	table           bool
+	headerTag       *regexp.Regexp
+	columnNames     []string
+	columnSpecs     []ColumnSpec
+	renames         map[string]string
+	fixedWidth      bool
+	runes           bool
+	headerless      bool
+	delimiter       []byte
+	escape          byte
+	rawText         bool
+	reportUnmatched bool
+	reportSummary   bool
+	unmatchedCh     chan<- Meta
+	observers       []Observer
+	counters        *counters
+	errorPolicy     ErrorPolicy
+	errorCh         chan<- error
+	logfmt          bool
+	keyValue        *regexp.Regexp
+	keyGroups       [2]int // KeyValue key and value capture group index
+	keyTemplate     *Value
+	keyFormat       KeyFormat
+	allowKeys       map[string]bool
+	typedValues     bool
+	values          []*Value
 }
 
 // ParserOpt functional options for Parser
@@ -38,6 +65,8 @@ type ParserOpt func(*Parser) (err error)
 func NewParser(values []*Value, options ...ParserOpt) (p *Parser, err error) {
 	p = &Parser{}
 	p.startTag = rexDefaultStartTag
+	p.errorPolicy = CollectErrors
+	p.counters = &counters{}
 
 	for _, opt := range options {
 		if err = opt(p); err != nil {
@@ -45,6 +74,67 @@ func NewParser(values []*Value, options ...ParserOpt) (p *Parser, err error) {
 		}
 	}
 	p.values = values
+
+	// Create the values for LineRegex pattern fields that were not specified
+	if p.patternFields != nil {
+		p.values = append([]*Value(nil), values...)
+	}
+	for _, field := range p.patternFields {
+		if _, ok := p.value(field.name); ok {
+			continue
+		}
+
+		v, err := NewValue(field.name, field.valueType)
+		if err != nil {
+			return nil, err
+		}
+		p.values = append(p.values, v)
+	}
+
+	if p.table && p.regex != nil {
+		return nil, fmt.Errorf("table mode cannot be used with a line regex")
+	}
+
+	if p.keyValue != nil {
+		if p.table || p.regex != nil {
+			return nil, fmt.Errorf("key value mode cannot be used with table mode or a line regex")
+		}
+
+		// Without a StartTag the whole data is a single document
+		if p.startTag == rexDefaultStartTag {
+			p.startTag = nil
+		}
+	}
+
+	if p.logfmt && (p.table || p.regex != nil || p.keyValue != nil) {
+		return nil, fmt.Errorf("logfmt mode cannot be used with table, key value mode or a line regex")
+	}
+
+	if p.errorPolicy == SeparateErrors && p.errorCh == nil {
+		return nil, fmt.Errorf("separate errors policy requires an error channel")
+	}
+
+	if p.fixedWidth && p.delimiter != nil {
+		return nil, fmt.Errorf("fixed width columns cannot be used with a delimiter")
+	}
+
+	if p.table && (p.columnSpecs != nil || p.headerless) {
+		if p.columnNames, err = p.specNames(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Handle multiline regexps
+	if p.regex != nil && strings.HasPrefix(p.regex.String(), "(?m)") {
+		p.multiLine = true
+	}
+
+	if p.regex != nil && !p.findAll {
+		if p.groups, err = p.lineGroups(); err != nil {
+			return nil, err
+		}
+	}
+
 	return p, nil
 }
 
@@ -68,14 +158,31 @@ func TrimSpaces() (opt ParserOpt) {
 // LineRegex sets a regexp for this parser making the extraction to work in line mode and ignore all Values regexps.
 // Working in line mode is much faster than in Set using Value regexp.
 // Multiline regexps `(?m)` are still valid, but usually are slower than using Values regexps.
+//
+// Regexps in the LineRegex, Value and tag options can reference named patterns as `%{NAME}`.
+// In the LineRegex the `%{NAME:field}` and `%{NAME:field:type}` references create a String
+// or typed Value for the field, unless a Value with the same name is specified.
+//
+// If the regexp has named capture groups `(?P<name>re)` they are bound to the Value with the same name,
+// unnamed groups are ignored and the Values order does not matter. Otherwise capture groups are bound
+// to Values by position.
 func LineRegex(expr string) (opt ParserOpt) {
 	return func(p *Parser) (err error) {
-		regex, err := regexp.Compile(expr)
+		regex, fields, err := compilePatterns(expr)
 		p.regex = regex
+		p.patternFields = fields
 		return err
 	}
 }
 
+// RawText sets the parser to keep the raw text of the lines that made up each Result in its Meta
+func RawText() (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.rawText = true
+		return nil
+	}
+}
+
 // FindAll successive matches for the specified LineRegex
 func FindAll() (opt ParserOpt) {
 	return func(p *Parser) (err error) {
@@ -88,7 +195,7 @@ func FindAll() (opt ParserOpt) {
 // when working in Set mode (Value regexp)
 func StartTag(expr string) (opt ParserOpt) {
 	return func(p *Parser) (err error) {
-		regex, err := regexp.Compile(expr)
+		regex, err := compileRegex(expr)
 		p.startTag = regex
 		return err
 	}
@@ -97,7 +204,7 @@ func StartTag(expr string) (opt ParserOpt) {
 // StopTag sets a regexp that when match will stop the parser
 func StopTag(expr string) (opt ParserOpt) {
 	return func(p *Parser) (err error) {
-		regex, err := regexp.Compile(expr)
+		regex, err := compileRegex(expr)
 		p.stopTag = regex
 		return err
 	}
@@ -106,7 +213,7 @@ func StopTag(expr string) (opt ParserOpt) {
 // SkipTag sets a regexp that when match the parser will skip lines until ContinueTag
 func SkipTag(expr string) (opt ParserOpt) {
 	return func(p *Parser) (err error) {
-		regex, err := regexp.Compile(expr)
+		regex, err := compileRegex(expr)
 		p.skipTag = regex
 		return err
 	}
@@ -115,7 +222,7 @@ func SkipTag(expr string) (opt ParserOpt) {
 // ContinueTag sets a regexp that when match the parser will resume after SkipTag
 func ContinueTag(expr string) (opt ParserOpt) {
 	return func(p *Parser) (err error) {
-		regex, err := regexp.Compile(expr)
+		regex, err := compileRegex(expr)
 		p.continueTag = regex
 		return err
 	}
@@ -125,12 +232,13 @@ func ContinueTag(expr string) (opt ParserOpt) {
 func (p *Parser) Parse(ctx context.Context, data io.Reader) (results <-chan Result) {
 	resultCh := make(chan Result)
 
-	if p.regex == nil {
-		go p.parseSet(ctx, data, resultCh)
-		return resultCh
-	}
+	go func() {
+		defer close(resultCh)
+		p.run(ctx, data, func(result Result) bool {
+			return wrapCtxSend(ctx, result, resultCh)
+		})
+	}()
 
-	go p.parse(ctx, data, resultCh)
 	return resultCh
 }
 
@@ -139,29 +247,67 @@ func (p *Parser) ParseBytes(ctx context.Context, data []byte) (results <-chan Re
 	return p.Parse(ctx, bytes.NewReader(data))
 }
 
-func (p *Parser) parse(ctx context.Context, data io.Reader, results chan<- Result) {
-	defer close(results)
+// ParseFunc parses raw data calling fn for each result on the caller goroutine.
+// Parsing stops when fn returns an error, which is returned, or when the context is done
+func (p *Parser) ParseFunc(ctx context.Context, data io.Reader, fn func(Result) error) (err error) {
+	p.run(ctx, data, func(result Result) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		err = fn(result)
+		return err == nil
+	})
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+// ParseSeq returns an iterator that parses raw data on the caller goroutine when ranged over.
+// Each result is yielded with a nil error, and the context error is yielded last if it is done.
+func (p *Parser) ParseSeq(ctx context.Context, data io.Reader) (seq iter.Seq2[Result, error]) {
+	return func(yield func(Result, error) bool) {
+		err := p.ParseFunc(ctx, data, func(result Result) error {
+			if !yield(result, nil) {
+				return errStop
+			}
+			return nil
+		})
+
+		if err != nil && err != errStop {
+			yield(Result{}, err)
+		}
+	}
+}
+
+// run parses the data in the parser mode delivering the results to emit
+func (p *Parser) run(ctx context.Context, data io.Reader, emit emitFunc) {
+	switch {
+	case p.table:
+		p.parseRows(ctx, data, emit)
+	case p.logfmt:
+		p.parseLogfmt(ctx, data, emit)
+	case p.regex == nil:
+		p.parseSet(ctx, data, emit)
+	default:
+		p.parse(ctx, data, emit)
+	}
+}
 
+func (p *Parser) parse(ctx context.Context, data io.Reader, emit emitFunc) {
 	var skip bool
 	var line []byte
 	var match [][]byte
+	var meta Meta
 	var result Result
+	var buffered []int
 	var buff bytes.Buffer
-	scanner := bufio.NewScanner(data)
-
-	// Handle multiline regexps
-	if strings.HasPrefix(p.regex.String(), "(?m)") {
-		p.multiLine = true
-	}
+	scanner := newLineScanner(data)
+	track := p.newTracker()
 
 	for scanner.Scan() {
-		if err := scanner.Err(); err != nil {
-			result = Result{}
-			result.Errors = append(result.Errors, err)
-			wrapCtxSend(ctx, result, results)
-			return
-		}
-
+		track.read(scanner.Line())
 		if p.trimSpaces {
 			line = bytes.TrimSpace(scanner.Bytes())
 		} else {
@@ -169,27 +315,24 @@ func (p *Parser) parse(ctx context.Context, data io.Reader, results chan<- Resul
 		}
 
 		if p.stopTag != nil && p.stopTag.Match(line) {
+			track.stopped(scanner.Line())
 			break
 		}
 
-		// Only skip sections if both skip and continue regexps are set
-		if p.skipTag != nil && p.continueTag != nil {
-			// Set the skip flag if skipTag is set and match the current line
-			if p.skipTag.Match(line) {
-				skip = true
-			}
-			//  Set the skip flag if continueTag is set and match the current line
-			if p.continueTag.Match(line) {
-				skip = false
-			}
+		if p.skipLine(line, &skip) {
+			track.skipped(scanner.Line())
+			continue
+		}
 
-			if skip {
-				continue
-			}
+		// Lines are also accumulated in the metadata till match when multiline (?m)
+		if !p.multiLine {
+			meta = Meta{}
 		}
+		scanner.meta(&meta, line, p.rawText)
 
 		// Buffer lines till match when multiline (?m)
 		if p.multiLine {
+			buffered = append(buffered, scanner.Line())
 			if buff.Len() > 0 {
 				buff.WriteByte('\n')
 			}
@@ -208,59 +351,101 @@ func (p *Parser) parse(ctx context.Context, data io.Reader, results chan<- Resul
 		}
 
 		if match == nil {
+			if !p.multiLine && len(bytes.TrimSpace(line)) > 0 {
+				if !p.unmatched(ctx, emit, track, scanner, line) {
+					return
+				}
+			}
 			continue
 		}
 
-		match = match[1:]
-		if len(match) != len(p.values) {
-			result = Result{}
-			result.Errors = append(result.Errors, errInvalidParsersNumber)
-			if !wrapCtxSend(ctx, result, results) {
+		if p.multiLine {
+			for _, n := range buffered {
+				track.matched(n)
+			}
+			buffered = buffered[:0]
+		} else {
+			track.matched(scanner.Line())
+		}
+
+		if p.findAll && len(match)-1 != len(p.values) {
+			result = Result{Meta: meta}
+			result.Errors = append(result.Errors, &ParseError{Line: scanner.Line(), Offset: scanner.Offset(),
+				Input: string(line), Err: ErrCaptureCount})
+			if !p.send(ctx, track, result, emit) {
 				return
 			}
 			continue
 		}
 
-		result = Result{}
+		result = Result{Meta: meta}
 		result.Data = newJSON()
 
 		for vp := range p.values {
 
-			value, _, err := p.values[vp].Parse(match[vp])
+			group := vp + 1
+			if p.groups != nil {
+				group = p.groups[vp]
+			}
+
+			// Optional capture groups that did not participate in the match are null
+			if match[group] == nil {
+				p.set(&result, p.values[vp], nil, p.values[vp].path)
+				continue
+			}
+
+			value, _, err := p.values[vp].Parse(match[group])
 			if err != nil {
-				err = fmt.Errorf("error parsing %s, %s", p.values[vp].name, err.Error())
-				result.Errors = append(result.Errors, err)
+				result.Errors = append(result.Errors,
+					lineError(err, p.values[vp].name, scanner.Line(), scanner.Offset()))
 			}
 
-			result.Data, _ = jsonSet(result.Data, value, p.values[vp].name)
+			p.set(&result, p.values[vp], value, p.values[vp].path)
 		}
 
 		if p.multiLine {
 			buff.Reset()
+			meta = Meta{}
 		}
 
-		if !wrapCtxSend(ctx, result, results) {
+		if !p.send(ctx, track, result, emit) {
 			return
 		}
 	}
 
-}
+	// Lines buffered without a match when multiline (?m)
+	if len(buffered) > 0 {
+		for _, n := range buffered {
+			track.unmatched(n)
+		}
+		if p.reportUnmatched || p.unmatchedCh != nil {
+			meta.Raw = append([]byte(nil), buff.Bytes()...)
+			if !p.sendUnmatched(ctx, emit, meta) {
+				return
+			}
+		}
+	}
 
-func (p *Parser) parseSet(ctx context.Context, data io.Reader, results chan<- Result) {
-	defer close(results)
+	if !p.sendScanError(ctx, track, scanner, emit) {
+		return
+	}
+
+	p.sendSummary(ctx, emit, track.summary)
+}
 
+func (p *Parser) parseSet(ctx context.Context, data io.Reader, emit emitFunc) {
 	var skip bool
 	var result Result
-	scanner := bufio.NewScanner(data)
+	scanner := newLineScanner(data)
+	track := p.newTracker()
 
-	for scanner.Scan() {
-		if err := scanner.Err(); err != nil {
-			result = Result{}
-			result.Errors = append(result.Errors, err)
-			wrapCtxSend(ctx, result, results)
-			return
-		}
+	// Without a StartTag the whole data is a single document
+	if p.startTag == nil {
+		result.Data = newJSON()
+	}
 
+	for scanner.Scan() {
+		track.read(scanner.Line())
 		line := scanner.Bytes()
 		if len(line) == 0 {
 			continue
@@ -271,65 +456,102 @@ func (p *Parser) parseSet(ctx context.Context, data io.Reader, results chan<- Re
 		}
 
 		if p.stopTag != nil && p.stopTag.Match(line) {
+			track.stopped(scanner.Line())
 			break
 		}
 
-		// Only skip sections if both skip and continue regexps are set
-		if p.skipTag != nil && p.continueTag != nil {
-			// Set the skip flag if KeySkipTag is set and match the current line
-			if p.skipTag.Match(line) {
-				skip = true
-			}
-			//  Set the skip flag if KeyContinueTag is set and match the current line
-			if p.continueTag.Match(line) {
-				skip = false
-			}
-
-			if skip {
-				continue
-			}
+		if p.skipLine(line, &skip) {
+			track.skipped(scanner.Line())
+			continue
 		}
 
 		// If content is a match for start_tag and
 		// document is valid deliver the result
-		if p.startTag.Match(line) {
+		var matched bool
+		if p.startTag != nil && p.startTag.Match(line) {
+			matched = p.startTag != rexDefaultStartTag
 			if len(result.Data) > 0 || result.Errors != nil {
-				if !wrapCtxSend(ctx, result, results) {
+				if !p.send(ctx, track, result, emit) {
 					return
 				}
 			}
 			result = Result{}
 			result.Data = newJSON()
 		}
+		scanner.meta(&result.Meta, line, p.rawText)
+
+		if p.keyValue != nil {
+			var ok bool
+			result, ok = p.parseKeyValue(line, result, scanner)
+			matched = matched || ok
+		}
 
 		for vp := range p.values {
 
+			// Values without regexp only type the KeyValue fields
+			if p.keyValue != nil && p.values[vp].regex == nil {
+				continue
+			}
+
 			// Continue if we already have a match for this regexp
-			if jsonHas(result.Data, p.values[vp].name) {
+			if jsonHasPath(result.Data, p.values[vp].path) {
+				if !matched && p.values[vp].regex != nil {
+					matched = p.values[vp].regex.Match(line)
+				}
 				continue
 			}
 
 			// Continue if we don't match this regexp
 			value, ok, err := p.values[vp].Parse(line)
+			matched = matched || ok
 			if err != nil {
-				result.Errors = append(
-					result.Errors,
-					fmt.Errorf("error parsing %s, %s", p.values[vp].name, err.Error()),
-				)
+				result.Errors = append(result.Errors,
+					lineError(err, p.values[vp].name, scanner.Line(), scanner.Offset()))
 				continue
 			}
 
 			if ok {
-				result.Data, _ = jsonSet(result.Data, value, p.values[vp].name)
+				p.set(&result, p.values[vp], value, p.values[vp].path)
 			}
 		}
+
+		if matched {
+			track.matched(scanner.Line())
+		} else if !p.unmatched(ctx, emit, track, scanner, line) {
+			return
+		}
 	}
 
 	if result.Data != nil || result.Errors != nil {
-		if !wrapCtxSend(ctx, result, results) {
+		if !p.send(ctx, track, result, emit) {
 			return
 		}
 	}
+
+	if !p.sendScanError(ctx, track, scanner, emit) {
+		return
+	}
+
+	p.sendSummary(ctx, emit, track.summary)
+}
+
+// skipLine reports whether the line is in a section between the SkipTag and ContinueTag
+func (p *Parser) skipLine(line []byte, skip *bool) (ok bool) {
+	// Only skip sections if both skip and continue regexps are set
+	if p.skipTag == nil || p.continueTag == nil {
+		return false
+	}
+
+	// Set the skip flag if skipTag is set and match the current line
+	if p.skipTag.Match(line) {
+		*skip = true
+	}
+	//  Set the skip flag if continueTag is set and match the current line
+	if p.continueTag.Match(line) {
+		*skip = false
+	}
+
+	return *skip
 }
 
 func (p *Parser) handleAllSubmatch(data []byte) (match [][]byte) {
@@ -344,3 +566,57 @@ func (p *Parser) handleAllSubmatch(data []byte) (match [][]byte) {
 	}
 	return match
 }
+
+// lineGroups maps each value to a LineRegex capture group by name if
+// the regexp has named capture groups, or by position otherwise
+func (p *Parser) lineGroups() (groups []int, err error) {
+	names := p.regex.SubexpNames()
+	index := map[string]int{}
+	for i, name := range names {
+		if name != "" {
+			index[name] = i
+		}
+	}
+
+	groups = make([]int, len(p.values))
+
+	if len(index) == 0 {
+		if p.regex.NumSubexp() != len(p.values) {
+			return nil, fmt.Errorf("line regex has %d capture groups for %d values",
+				p.regex.NumSubexp(), len(p.values))
+		}
+		for vp := range p.values {
+			groups[vp] = vp + 1
+		}
+		return groups, nil
+	}
+
+	for vp := range p.values {
+		i, ok := index[p.values[vp].name]
+		if !ok {
+			return nil, fmt.Errorf("line regex has no capture group named %s", p.values[vp].name)
+		}
+		groups[vp] = i
+	}
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, ok := p.value(name); !ok {
+			return nil, fmt.Errorf("line regex capture group %s has no matching value", name)
+		}
+	}
+
+	return groups, nil
+}
+
+// value returns the value with the given name
+func (p *Parser) value(name string) (v *Value, ok bool) {
+	for vp := range p.values {
+		if p.values[vp].name == name {
+			return p.values[vp], true
+		}
+	}
+	return nil, false
+}
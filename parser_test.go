@@ -1,7 +1,9 @@
 package rexon
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"testing"
 )
 
@@ -114,6 +116,23 @@ func BenchmarkLineLine(b *testing.B) {
 	}
 }
 
+func BenchmarkLineLineFunc(b *testing.B) {
+	values := []*Value{
+		MustNewValue("maj", Number),
+		MustNewValue("min", Number),
+		MustNewValue("device", String)}
+
+	p, err := NewParser(values, LineRegex(`(\d+)\s+(\d+)\s+(.*?)\s+`))
+
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		p.ParseFunc(context.Background(), bytes.NewReader(dataMLine), func(Result) error { return nil })
+	}
+}
+
 func BenchmarkLineSet(b *testing.B) {
 	values := []*Value{
 		MustNewValue("maj", Number, Round(2), ValueRegex(`(\d+)\s+\d+\s+.*?\s+`)),
@@ -170,3 +189,315 @@ func BenchmarkMLineSet(b *testing.B) {
 		}
 	}
 }
+
+func TestParserLineNamedGroups(t *testing.T) {
+	values := []*Value{
+		MustNewValue("device", String),
+		MustNewValue("maj", Number)}
+
+	p, err := NewParser(values, LineRegex(`(?P<maj>\d+)\s+(\d+)\s+(?P<device>.*?)\s+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for d := range p.ParseBytes(context.Background(), []byte(`8       1 sda1 193 84 10754`)) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != `{"device":"sda1","maj":8}` {
+			t.Fatalf("unexpected result: %s", d.Data)
+		}
+	}
+
+	if _, err = NewParser(values, LineRegex(`(?P<maj>\d+)\s+(?P<min>\d+)\s+(?P<device>.*?)\s+`)); err == nil {
+		t.Fatal("expected error for capture group without value")
+	}
+
+	if _, err = NewParser(values, LineRegex(`(?P<maj>\d+)\s+(\d+)\s+`)); err == nil {
+		t.Fatal("expected error for value without capture group")
+	}
+
+	if _, err = NewParser(values, LineRegex(`(\d+)\s+(\d+)\s+(.*?)\s+`)); err == nil {
+		t.Fatal("expected error for positional capture group count mismatch")
+	}
+}
+
+func TestParserNestedPaths(t *testing.T) {
+	values := []*Value{
+		MustNewValue("host", String, ValueRegex(`host\s+(\w+)`)),
+		MustNewValue("disks[].name", String, ValueRegex(`disk\s+(\w+)`)),
+		MustNewValue("size", Number, Path("disks[]", "size", "bytes"), ValueRegex(`size\s+(\d+)`))}
+
+	p, err := NewParser(values, StartTag(`^host`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("host a\ndisk sda\nsize 10\ndisk sdb\nsize 20\nhost b\ndisk sdc\n")
+	expected := []string{
+		`{"host":"a","disks":[{"name":"sda","size":{"bytes":10}},{"name":"sdb","size":{"bytes":20}}]}`,
+		`{"host":"b","disks":[{"name":"sdc"}]}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), data) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+}
+
+func TestParserLineInteger(t *testing.T) {
+	values := []*Value{
+		MustNewValue("device", String),
+		MustNewValue("sectors", Integer)}
+
+	p, err := NewParser(values, LineRegex(`(\S+)\s+(\d+)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for d := range p.ParseBytes(context.Background(), []byte(`sdb 18446744073709551615`)) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != `{"device":"sdb","sectors":18446744073709551615}` {
+			t.Fatalf("unexpected result: %s", d.Data)
+		}
+	}
+}
+
+func TestParserMeta(t *testing.T) {
+	values := []*Value{
+		MustNewValue("host", String, ValueRegex(`host\s+(\w+)`)),
+		MustNewValue("disks[]", String, ValueRegex(`disk\s+(\w+)`))}
+
+	p, err := NewParser(values, StartTag(`^host`), RawText())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("host a\ndisk sda\ndisk sdb\nhost b\r\ndisk sdc\n")
+	expected := []Meta{
+		{FirstLine: 1, LastLine: 3, Start: 0, End: 25, Raw: []byte("host a\ndisk sda\ndisk sdb")},
+		{FirstLine: 4, LastLine: 5, Start: 25, End: 42, Raw: []byte("host b\ndisk sdc")},
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), data) {
+		m := d.Meta
+		e := expected[count]
+		if m.FirstLine != e.FirstLine || m.LastLine != e.LastLine || m.Start != e.Start ||
+			m.End != e.End || string(m.Raw) != string(e.Raw) {
+			t.Fatalf("expected %#v, got %#v", e, m)
+		}
+		count++
+	}
+
+	p, err = NewParser([]*Value{MustNewValue("id", Integer)}, LineRegex(`id=(\d+)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for d := range p.ParseBytes(context.Background(), []byte("x\nid=1\n")) {
+		b, _ := d.Meta.MarshalJSON()
+		if string(b) != `{"first_line":2,"last_line":2,"start":2,"end":7}` {
+			t.Fatalf("unexpected metadata: %s", b)
+		}
+	}
+}
+
+func TestParserParseFunc(t *testing.T) {
+	values := []*Value{
+		MustNewValue("maj", Number),
+		MustNewValue("min", Number),
+		MustNewValue("device", String)}
+
+	p, err := NewParser(values, LineRegex(`(\d+)\s+(\d+)\s+(.*?)\s+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []string
+	for result := range p.ParseBytes(context.Background(), dataLine) {
+		expected = append(expected, string(result.Data))
+	}
+
+	var count int
+	err = p.ParseFunc(context.Background(), bytes.NewReader(dataLine), func(result Result) error {
+		if string(result.Data) != expected[count] {
+			t.Fatalf("expected %s, got: %s", expected[count], result.Data)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(expected) {
+		t.Fatalf("expected %d results, got: %d", len(expected), count)
+	}
+
+	errDone := errors.New("done")
+	count = 0
+	err = p.ParseFunc(context.Background(), bytes.NewReader(dataLine), func(result Result) error {
+		count++
+		if count == 2 {
+			return errDone
+		}
+		return nil
+	})
+	if err != errDone {
+		t.Fatalf("expected %s, got: %v", errDone, err)
+	}
+	if count != 2 {
+		t.Fatalf("expected the parse to stop after 2 results, got: %d", count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count = 0
+	err = p.ParseFunc(ctx, bytes.NewReader(dataLine), func(result Result) error {
+		count++
+		cancel()
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected %s, got: %v", context.Canceled, err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the parse to stop after 1 result, got: %d", count)
+	}
+}
+
+func TestParserParseSeq(t *testing.T) {
+	values := []*Value{
+		MustNewValue("maj", Number),
+		MustNewValue("min", Number),
+		MustNewValue("device", String)}
+
+	p, err := NewParser(values, LineRegex(`(\d+)\s+(\d+)\s+(.*?)\s+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	for result, err := range p.ParseSeq(context.Background(), bytes.NewReader(dataLine)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Data) == 0 {
+			t.Fatalf("expected data in result %d", count)
+		}
+		count++
+	}
+	if count != 12 {
+		t.Fatalf("expected 12 results, got: %d", count)
+	}
+
+	count = 0
+	for range p.ParseSeq(context.Background(), bytes.NewReader(dataLine)) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 {
+		t.Fatalf("expected the parse to stop after 3 results, got: %d", count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var last error
+	count = 0
+	for _, err := range p.ParseSeq(ctx, bytes.NewReader(dataLine)) {
+		if err != nil {
+			last = err
+			continue
+		}
+		count++
+		cancel()
+	}
+	if last != context.Canceled || count != 1 {
+		t.Fatalf("expected %s after 1 result, got: %v after %d", context.Canceled, last, count)
+	}
+}
+
+func TestParserOptionalGroups(t *testing.T) {
+	data := []byte(`  PID     ELAPSED CMD
+    1  2-03:04:05 init
+  812       05:30 bash -l
+ 1024          12 sleep`)
+
+	values := []*Value{
+		MustNewValue("pid", Integer),
+		MustNewValue("days", Integer),
+		MustNewValue("etime", Duration, ToFormat("seconds"), ShortClock(ClockMinuteSecond)),
+		MustNewValue("command", String)}
+
+	p, err := NewParser(values, LineRegex(`^\s*(\d+)\s+(?:(\d+)-)?([\d:]+)\s+(\S+)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"pid":1,"days":2,"etime":11045,"command":"init"}`,
+		`{"pid":812,"days":null,"etime":330,"command":"bash"}`,
+		`{"pid":1024,"days":null,"etime":12,"command":"sleep"}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), data) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+
+	if count != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), count)
+	}
+}
+
+func TestParserHumanDuration(t *testing.T) {
+	data := []byte(` 10:15:01 up 10 days,  3:04,  2 users,  load average: 0.15, 0.10, 0.05
+ 10:15:01 up 1 day, 10 min,  1 user,  load average: 0.00, 0.01, 0.05
+ 10:15:01 up 45 min,  1 user,  load average: 0.00, 0.01, 0.05`)
+
+	values := []*Value{
+		MustNewValue("uptime", Duration, ToFormat("seconds")),
+		MustNewValue("users", Integer)}
+
+	p, err := NewParser(values, LineRegex(`up\s+(.+?),\s+(\d+) users?`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"uptime":875040,"users":2}`,
+		`{"uptime":87000,"users":1}`,
+		`{"uptime":2700,"users":1}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), data) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+
+	if count != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), count)
+	}
+}
@@ -0,0 +1,194 @@
+package rexon
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const maxPatternDepth = 32
+
+var (
+	rexPattern     = regexp.MustCompile(`%\{(\w+)(?::(\w+))?(?::(\w+))?\}`)
+	rexPatternName = regexp.MustCompile(`^\w+$`)
+
+	patternsMu sync.RWMutex
+	patterns   = map[string]string{
+		"USERNAME":          `[a-zA-Z0-9._-]+`,
+		"USER":              `%{USERNAME}`,
+		"INT":               `[+-]?[0-9]+`,
+		"POSINT":            `[1-9][0-9]*`,
+		"NONNEGINT":         `[0-9]+`,
+		"BASE10NUM":         `[+-]?(?:[0-9]+(?:\.[0-9]+)?|\.[0-9]+)`,
+		"NUMBER":            `%{BASE10NUM}`,
+		"BASE16NUM":         `[+-]?(?:0x)?[0-9A-Fa-f]+`,
+		"WORD":              `\b\w+\b`,
+		"NOTSPACE":          `\S+`,
+		"SPACE":             `\s*`,
+		"DATA":              `.*?`,
+		"GREEDYDATA":        `.*`,
+		"QUOTEDSTRING":      `"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`,
+		"UUID":              `[A-Fa-f0-9]{8}-(?:[A-Fa-f0-9]{4}-){3}[A-Fa-f0-9]{12}`,
+		"MAC":               `(?:[A-Fa-f0-9]{2}[:-]){5}[A-Fa-f0-9]{2}|(?:[A-Fa-f0-9]{4}\.){2}[A-Fa-f0-9]{4}`,
+		"IPV4":              `(?:(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])`,
+		"IPV6":              `(?:[A-Fa-f0-9]{1,4}:){1,7}(?:(?::[A-Fa-f0-9]{1,4}){1,7}|:|[A-Fa-f0-9]{1,4})|::(?:[A-Fa-f0-9]{1,4}(?::[A-Fa-f0-9]{1,4}){0,6})?`,
+		"IP":                `%{IPV6}|%{IPV4}`,
+		"HOSTNAME":          `\b[0-9A-Za-z][0-9A-Za-z-]{0,62}(?:\.[0-9A-Za-z][0-9A-Za-z-]{0,62})*\.?\b`,
+		"IPORHOST":          `%{IP}|%{HOSTNAME}`,
+		"HOSTPORT":          `%{IPORHOST}:%{POSINT}`,
+		"UNIXPATH":          `(?:/[\w%!$@:.,+~-]*)+`,
+		"PATH":              `%{UNIXPATH}`,
+		"MONTH":             `\b(?:[Jj]an(?:uary)?|[Ff]eb(?:ruary)?|[Mm]ar(?:ch)?|[Aa]pr(?:il)?|[Mm]ay|[Jj]un(?:e)?|[Jj]ul(?:y)?|[Aa]ug(?:ust)?|[Ss]ep(?:tember)?|[Oo]ct(?:ober)?|[Nn]ov(?:ember)?|[Dd]ec(?:ember)?)\b`,
+		"MONTHNUM":          `0?[1-9]|1[0-2]`,
+		"MONTHDAY":          `0[1-9]|[12][0-9]|3[01]|[1-9]`,
+		"DAY":               `\b(?:Mon(?:day)?|Tue(?:sday)?|Wed(?:nesday)?|Thu(?:rsday)?|Fri(?:day)?|Sat(?:urday)?|Sun(?:day)?)\b`,
+		"YEAR":              `\d\d(?:\d\d)?`,
+		"HOUR":              `2[0123]|[01]?[0-9]`,
+		"MINUTE":            `[0-5][0-9]`,
+		"SECOND":            `(?:[0-5]?[0-9]|60)(?:[.,][0-9]+)?`,
+		"TIME":              `%{HOUR}:%{MINUTE}(?::%{SECOND})?`,
+		"DATE_US":           `%{MONTHNUM}[/-]%{MONTHDAY}[/-]%{YEAR}`,
+		"DATE_EU":           `%{MONTHDAY}[./-]%{MONTHNUM}[./-]%{YEAR}`,
+		"ISO8601_TIMEZONE":  `Z|[+-]%{HOUR}(?::?%{MINUTE})?`,
+		"TIMESTAMP_ISO8601": `%{YEAR}-%{MONTHNUM}-%{MONTHDAY}[T ]%{HOUR}:?%{MINUTE}(?::?%{SECOND})?(?:%{ISO8601_TIMEZONE})?`,
+		"SYSLOGTIMESTAMP":   `%{MONTH} +%{MONTHDAY} %{TIME}`,
+		"SYSLOGHOST":        `%{IPORHOST}`,
+		"PROG":              `[\x21-\x5a\x5c\x5e-\x7e]+`,
+		"SYSLOGPROG":        `%{PROG:program}(?:\[%{POSINT:pid:integer}\])?`,
+		"LOGLEVEL":          `[Aa]lert|ALERT|[Tt]race|TRACE|[Dd]ebug|DEBUG|[Nn]otice|NOTICE|[Ii]nfo|INFO|[Ww]arn(?:ing)?|WARN(?:ING)?|[Ee]rr(?:or)?|ERR(?:OR)?|[Cc]rit(?:ical)?|CRIT(?:ICAL)?|[Ff]atal|FATAL|[Ss]evere|SEVERE|[Ee]merg(?:ency)?|EMERG(?:ENCY)?`,
+		"DIGITALUNIT":       `[+-]?(?:[0-9]+(?:\.[0-9]+)?|\.[0-9]+)\s*[A-Za-z]*`,
+		"DURATION":          `(?:[+-]?(?:[0-9]+(?:\.[0-9]+)?|\.[0-9]+)(?:ns|us|µs|ms|s|m|h)?)+`,
+	}
+
+	patternTypes = map[string]ValueType{
+		"int":   Integer,
+		"float": Number,
+	}
+)
+
+// patternField is a named field from a pattern reference
+type patternField struct {
+	name      string
+	valueType ValueType
+}
+
+// RegisterPattern registers a named pattern that can be referenced in any regexp as `%{NAME}`,
+// `%{NAME:field}` for a named capture group or `%{NAME:field:type}` for a typed capture group.
+// Named and typed references are only supported in a LineRegex.
+// Patterns can reference other patterns and replace an existing pattern with the same name.
+func RegisterPattern(name, expr string) (err error) {
+	if !rexPatternName.MatchString(name) {
+		return fmt.Errorf("invalid pattern name: %s", name)
+	}
+
+	expanded, _, err := expandPatterns(expr, true)
+	if err != nil {
+		return err
+	}
+
+	if _, err = regexp.Compile(expanded); err != nil {
+		return fmt.Errorf("invalid pattern %s, %s", name, err.Error())
+	}
+
+	patternsMu.Lock()
+	patterns[name] = expr
+	patternsMu.Unlock()
+	return nil
+}
+
+// compileRegex expands the pattern references in the expression and compiles it.
+// Named pattern references are only supported in a LineRegex, so they are rejected
+// in the expression and the named fields of the referenced patterns are not captured
+func compileRegex(expr string) (regex *regexp.Regexp, err error) {
+	if expr, _, err = expandPatterns(expr, false); err != nil {
+		return nil, err
+	}
+	return regexp.Compile(expr)
+}
+
+// compilePatterns expands the pattern references in the expression, compiles it
+// and returns the named fields referenced
+func compilePatterns(expr string) (regex *regexp.Regexp, fields []patternField, err error) {
+	if expr, fields, err = expandPatterns(expr, true); err != nil {
+		return nil, nil, err
+	}
+
+	regex, err = regexp.Compile(expr)
+	return regex, fields, err
+}
+
+// expandPatterns replaces the pattern references in the expression with their regexps.
+// Without named fields, named references are rejected in the expression and
+// replaced by non capturing groups in the referenced patterns
+func expandPatterns(expr string, named bool) (expanded string, fields []patternField, err error) {
+	if !strings.Contains(expr, "%{") {
+		return expr, nil, nil
+	}
+
+	patternsMu.RLock()
+	defer patternsMu.RUnlock()
+
+	expanded, err = expandDepth(expr, 0, named, &fields)
+	return expanded, fields, err
+}
+
+func expandDepth(expr string, depth int, named bool, fields *[]patternField) (expanded string, err error) {
+	if depth > maxPatternDepth {
+		return "", fmt.Errorf("pattern recursion too deep: %s", expr)
+	}
+
+	expanded = rexPattern.ReplaceAllStringFunc(expr, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+
+		match := rexPattern.FindStringSubmatch(ref)
+		pattern, ok := patterns[match[1]]
+		if !ok {
+			err = fmt.Errorf("unknown pattern: %s", match[1])
+			return ""
+		}
+
+		if pattern, err = expandDepth(pattern, depth+1, named, fields); err != nil {
+			return ""
+		}
+
+		if match[2] != "" && !named && depth == 0 {
+			err = fmt.Errorf("named pattern reference %s is only supported in a line regex", ref)
+			return ""
+		}
+
+		if match[2] == "" || !named {
+			return "(?:" + pattern + ")"
+		}
+
+		field := patternField{name: match[2], valueType: String}
+		if match[3] != "" {
+			if field.valueType, err = patternType(match[3]); err != nil {
+				return ""
+			}
+		}
+		*fields = append(*fields, field)
+
+		return "(?P<" + match[2] + ">" + pattern + ")"
+	})
+
+	return expanded, err
+}
+
+// patternType returns the ValueType for the pattern field type
+func patternType(name string) (vt ValueType, err error) {
+	if vt, ok := patternTypes[name]; ok {
+		return vt, nil
+	}
+
+	switch vt = ValueType(name); vt {
+	case String, Number, Integer, Bool, Duration, DigitalUnit:
+		return vt, nil
+	case Time:
+		return "", fmt.Errorf("unsupported pattern type %s, use a Value with FromFormat", name)
+	}
+
+	return "", fmt.Errorf("unsupported pattern type %s", name)
+}
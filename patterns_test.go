@@ -0,0 +1,118 @@
+package rexon
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPatternsLineRegex(t *testing.T) {
+	data := []byte(`Oct 16 09:12:01 web01 sshd[1234]: Accepted publickey for root from 10.0.0.12 port 53512
+Oct  6 19:02:11 web01 cron[99]: session opened from fe80::1 port 22`)
+
+	values := []*Value{
+		MustNewValue("port", Integer)}
+
+	p, err := NewParser(values, LineRegex(
+		`^%{SYSLOGTIMESTAMP:timestamp} %{SYSLOGHOST:host} %{SYSLOGPROG}: %{DATA:message} from %{IP:src} port %{POSINT:port}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"port":53512,"timestamp":"Oct 16 09:12:01","host":"web01","program":"sshd","pid":1234,"message":"Accepted publickey for root","src":"10.0.0.12"}`,
+		`{"port":22,"timestamp":"Oct  6 19:02:11","host":"web01","program":"cron","pid":99,"message":"session opened","src":"fe80::1"}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), data) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+
+	if count != 2 {
+		t.Fatalf("expected 2 results, got %d", count)
+	}
+}
+
+func TestPatternsValueRegex(t *testing.T) {
+	v, err := NewValue("id", Number, ValueRegex(`id\s+(%{NUMBER})`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, ok, err := v.Parse([]byte(`id  -12.5`))
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+
+	if value != -12.5 {
+		t.Fatalf("expected -12.5, got %v", value)
+	}
+}
+
+func TestPatternsValueRegexNamed(t *testing.T) {
+	v, err := NewValue("program", String, ValueRegex(`^\S+ (%{SYSLOGPROG}):`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, ok, err := v.Parse([]byte(`web01 sshd[1234]: Accepted`))
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+
+	if value != "sshd[1234]" {
+		t.Fatalf("expected sshd[1234], got %v", value)
+	}
+
+	for _, expr := range []string{`(%{NUMBER:id})`, `(%{NUMBER:id:int})`} {
+		if _, err = NewValue("id", Number, ValueRegex(expr)); err == nil {
+			t.Fatalf("expected error for the named reference in %s", expr)
+		}
+	}
+
+	if _, err = NewParser(nil, StartTag(`%{WORD:name}`)); err == nil {
+		t.Fatal("expected error for a named reference in a tag")
+	}
+}
+
+func TestRegisterPattern(t *testing.T) {
+	if err := RegisterPattern("DEVICE", `(?:sd|hd|vd)[a-z]+%{NONNEGINT}?`); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewParser(nil, LineRegex(`%{DEVICE:device}\s+%{NUMBER:reads:int}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for d := range p.ParseBytes(context.Background(), []byte(`sda1 193`)) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != `{"device":"sda1","reads":193}` {
+			t.Fatalf("unexpected result: %s", d.Data)
+		}
+	}
+
+	invalid := []struct{ name, expr string }{
+		{"BAD NAME", `\d+`},
+		{"BAD", `(`},
+		{"BAD", `%{UNKNOWN}`},
+	}
+
+	for _, test := range invalid {
+		if err = RegisterPattern(test.name, test.expr); err == nil {
+			t.Fatalf("expected error for %s: %s", test.name, test.expr)
+		}
+	}
+
+	if _, err = NewParser(nil, LineRegex(`%{NUMBER:x:time}`)); err == nil {
+		t.Fatal("expected error for time pattern type")
+	}
+}
@@ -0,0 +1,121 @@
+package rexon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+)
+
+// ErrorPolicy defines how records with errors are handled
+type ErrorPolicy string
+
+const (
+	// CollectErrors sends the records with their errors in Result.Errors. This is the default
+	CollectErrors ErrorPolicy = "collect"
+	// FailFast sends the first record with errors and stops parsing
+	FailFast ErrorPolicy = "fail_fast"
+	// SkipRecord drops the records with errors
+	SkipRecord ErrorPolicy = "skip_record"
+	// SeparateErrors sends the errors on the ErrorChannel and the records without them
+	SeparateErrors ErrorPolicy = "separate"
+)
+
+// OnError sets the policy for records with errors.
+// Records rejected by a Required Value are never sent with their data.
+func OnError(policy ErrorPolicy) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		switch policy {
+		case CollectErrors, FailFast, SkipRecord, SeparateErrors:
+			p.errorPolicy = policy
+			return nil
+		}
+		return fmt.Errorf("unsupported error policy %s", policy)
+	}
+}
+
+// ErrorChannel sets the SeparateErrors policy sending the errors to the given channel.
+// The channel is not closed by the parser and must be read while parsing.
+func ErrorChannel(errors chan<- error) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		if errors == nil {
+			return fmt.Errorf("nil error channel")
+		}
+		p.errorPolicy = SeparateErrors
+		p.errorCh = errors
+		return nil
+	}
+}
+
+// send checks the required values and sends the record according to the error policy.
+// Reports false if parsing must stop
+func (p *Parser) send(ctx context.Context, track *tracker, result Result, emit emitFunc) (ok bool) {
+	if result.Data != nil && p.checkRequired(&result) {
+		result.Data = nil
+	}
+
+	if result.Errors == nil {
+		return track.emit(result, emit)
+	}
+
+	for _, err := range result.Errors {
+		track.failed(err)
+	}
+
+	switch p.errorPolicy {
+	case FailFast:
+		track.emit(result, emit)
+		return false
+
+	case SkipRecord:
+		return true
+
+	case SeparateErrors:
+		for _, err := range result.Errors {
+			select {
+			case <-ctx.Done():
+				return false
+			case p.errorCh <- err:
+			}
+		}
+
+		if result.Data == nil {
+			return true
+		}
+		result.Errors = nil
+	}
+
+	return track.emit(result, emit)
+}
+
+// checkRequired adds an error for each required value missing or null in the record.
+// Reports whether the record was rejected
+func (p *Parser) checkRequired(result *Result) (rejected bool) {
+	for _, v := range p.values {
+		if !v.required {
+			continue
+		}
+
+		path := make([]string, len(v.path))
+		for i, key := range v.path {
+			if isArrayKey(key) {
+				path[i] = key[:len(key)-2]
+				path = path[:i+1]
+				break
+			}
+			path[i] = key
+		}
+
+		_, dataType, _, err := jsonparser.Get(result.Data, path...)
+		if err == nil && dataType != jsonparser.Null {
+			continue
+		}
+
+		perr := lineError(ErrMissingValue, v.name, result.Meta.FirstLine, result.Meta.Start)
+		perr.Type = v.valueType
+		result.Errors = append(result.Errors, perr)
+		rejected = true
+	}
+
+	return rejected
+}
@@ -0,0 +1,122 @@
+package rexon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var dataPolicy = []byte("a 1\nb x\nc 3\n")
+
+func policyValues(options ...ValueOpt) (values []*Value) {
+	return []*Value{
+		MustNewValue("name", String),
+		MustNewValue("value", Number, options...)}
+}
+
+func TestParserErrorPolicy(t *testing.T) {
+	tests := []struct {
+		policy  ErrorPolicy
+		results int
+		errors  int
+	}{
+		{CollectErrors, 3, 1},
+		{SkipRecord, 2, 0},
+		{FailFast, 2, 1},
+	}
+
+	for _, test := range tests {
+		p, err := NewParser(policyValues(), LineRegex(`(\w+)\s+(\S+)`), OnError(test.policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var results, errs int
+		for d := range p.ParseBytes(context.Background(), dataPolicy) {
+			results++
+			errs += len(d.Errors)
+		}
+
+		if results != test.results || errs != test.errors {
+			t.Fatalf("expected %d results and %d errors for %s, got %d and %d",
+				test.results, test.errors, test.policy, results, errs)
+		}
+	}
+}
+
+func TestParserErrorChannel(t *testing.T) {
+	errCh := make(chan error, 10)
+	p, err := NewParser(policyValues(), LineRegex(`(\w+)\s+(\S+)`), ErrorChannel(errCh))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results int
+	for d := range p.ParseBytes(context.Background(), dataPolicy) {
+		if d.Errors != nil {
+			t.Fatalf("unexpected errors in result: %v", d.Errors)
+		}
+		results++
+	}
+
+	if results != 3 || len(errCh) != 1 {
+		t.Fatalf("expected 3 results and 1 error, got %d and %d", results, len(errCh))
+	}
+
+	if _, err = NewParser(nil, OnError(SeparateErrors)); err == nil {
+		t.Fatal("expected error for separate errors without a channel")
+	}
+}
+
+func TestParserRequired(t *testing.T) {
+	p, err := NewParser(policyValues(Required()), LineRegex(`(\w+)\s+(\S+)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results []Result
+	for d := range p.ParseBytes(context.Background(), dataPolicy) {
+		results = append(results, d)
+	}
+
+	if len(results) != 3 || results[1].Data != nil {
+		t.Fatalf("expected the second record to be rejected, got: %#v", results)
+	}
+
+	var perr *ParseError
+	last := results[1].Errors[len(results[1].Errors)-1]
+	if !errors.Is(last, ErrMissingValue) || !errors.As(last, &perr) || perr.Value != "value" || perr.Line != 2 {
+		t.Fatalf("unexpected error: %#v", last)
+	}
+}
+
+func TestLoadParserErrorPolicy(t *testing.T) {
+	def := `
+line_regex: '(\w+)\s+(\S+)'
+on_error: skip_record
+values:
+  - name: name
+    type: string
+  - name: value
+    type: number
+    required: true
+`
+	p, err := LoadParser(strings.NewReader(def))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results int
+	for range p.ParseBytes(context.Background(), dataPolicy) {
+		results++
+	}
+
+	if results != 2 {
+		t.Fatalf("expected 2 results, got %d", results)
+	}
+
+	if _, err = LoadParser(strings.NewReader(`{"on_error": "separate", "line_regex": "(a)"}`)); err == nil {
+		t.Fatal("expected error for the separate errors policy")
+	}
+}
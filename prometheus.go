@@ -0,0 +1,58 @@
+package rexon
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// promMetrics are the Prometheus metric families for the Stats counters
+var promMetrics = []struct {
+	name  string
+	help  string
+	value func(s *Stats) int64
+}{
+	{"rexon_lines_read_total", "Lines read.", func(s *Stats) int64 { return s.LinesRead }},
+	{"rexon_lines_skipped_total", "Lines skipped between the skip and continue tags.", func(s *Stats) int64 { return s.LinesSkipped }},
+	{"rexon_lines_matched_total", "Lines matched by the parser.", func(s *Stats) int64 { return s.LinesMatched }},
+	{"rexon_lines_unmatched_total", "Lines not matched by the parser.", func(s *Stats) int64 { return s.LinesUnmatched }},
+	{"rexon_stop_tags_total", "Parses stopped by the stop tag.", func(s *Stats) int64 { return s.StopTags }},
+	{"rexon_value_errors_total", "Value parse errors and missing required values.", func(s *Stats) int64 { return s.ValueErrors }},
+	{"rexon_records_total", "Records sent.", func(s *Stats) int64 { return s.Records }},
+}
+
+// WritePrometheus writes the Stats of the given parsers in the Prometheus text exposition format,
+// labeled with the parser name, so it can be served by an http.Handler without dependencies.
+func WritePrometheus(w io.Writer, parsers map[string]*Parser) (err error) {
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	stats := make([]Stats, len(names))
+	for i, name := range names {
+		stats[i] = parsers[name].Stats()
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, m := range promMetrics {
+		bw.WriteString("# HELP " + m.name + " " + m.help + "\n")
+		bw.WriteString("# TYPE " + m.name + " counter\n")
+
+		for i, name := range names {
+			bw.WriteString(m.name + `{parser="` + promEscape(name) + `"} `)
+			bw.WriteString(strconv.FormatInt(m.value(&stats[i]), 10))
+			bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
+
+// promEscape escapes a Prometheus label value
+func promEscape(value string) (escaped string) {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,38 @@
+package rexon
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	p, err := NewParser([]*Value{MustNewValue("id", Integer)}, LineRegex(`^id (\d+)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range p.ParseBytes(context.Background(), []byte("id 1\nid 2\nx\n")) {
+	}
+
+	var buf bytes.Buffer
+	parsers := map[string]*Parser{"ids": p, `a"b`: MustNewParser(nil, Logfmt())}
+	if err = WritePrometheus(&buf, parsers); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"# HELP rexon_lines_read_total Lines read.\n# TYPE rexon_lines_read_total counter\n" +
+			`rexon_lines_read_total{parser="a\"b"} 0` + "\n" +
+			`rexon_lines_read_total{parser="ids"} 3` + "\n",
+		`rexon_lines_unmatched_total{parser="ids"} 1` + "\n",
+		`rexon_records_total{parser="ids"} 2` + "\n",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(buf.String(), e) {
+			t.Fatalf("expected %q in:\n%s", e, buf.String())
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package rexon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// parseAll returns the results data of a parse joined by new lines
+func parseAll(p *Parser, data []byte) (out string) {
+	var buf bytes.Buffer
+	for d := range p.ParseBytes(context.Background(), data) {
+		buf.Write(d.Data)
+		fmt.Fprintln(&buf, d.Errors)
+	}
+	return buf.String()
+}
+
+// TestParserConcurrent runs concurrent parses on shared parsers, run it with -race
+func TestParserConcurrent(t *testing.T) {
+	parsers := map[string]struct {
+		parser *Parser
+		data   []byte
+	}{
+		"line": {MustNewParser([]*Value{
+			MustNewValue("maj", Number),
+			MustNewValue("min", Number),
+			MustNewValue("device", String)}, LineRegex(`(\d+)\s+(\d+)\s+(.*?)\s+`)), dataLine},
+		"multiline": {MustNewParser([]*Value{
+			MustNewValue("message", String),
+			MustNewValue("id", Number),
+			MustNewValue("vmm", String),
+			MustNewValue("cdd", String)},
+			LineRegex(`(?m)\s*message\s*(\w+)\nid\s*([-+]?[0-9]*\.?[0-9]+)\nvmm\s*(\w+)\s*cdd\s*(\w+)`)), dataMLine},
+		"set": {MustNewParser([]*Value{
+			MustNewValue("message", String, ValueRegex(`\s*message\s*(\w+)\s*`)),
+			MustNewValue("id", Number, ValueRegex(`id\s*([-+]?[0-9]*\.?[0-9]+)\s*`))},
+			StartTag(`message.*`), ReportUnmatched(), ReportSummary()), dataMLine},
+		"table":     {MustNewParser([]*Value{MustNewValue("pid", Integer)}, Table(), ColumnRename("CMD", "command")), dataPs},
+		"delimited": {MustNewParser(nil, Table(), Delimited(",")), []byte("a,b\n1,\"x,y\"\n2,z\n")},
+		"keyvalue": {MustNewParser(nil, KeyValue(`^([^:]+):\s+(.*)$`, DigitalUnit),
+			NormalizeKeys(KeySnakeCase)), dataMeminfo},
+		"logfmt": {MustNewParser([]*Value{MustNewValue("dur", Duration)}, Logfmt(), OnError(SkipRecord)), dataLogfmt},
+	}
+
+	for name, test := range parsers {
+		expected := parseAll(test.parser, test.data)
+
+		var wg sync.WaitGroup
+		errs := make(chan string, 32)
+		for i := 0; i < 32; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if out := parseAll(test.parser, test.data); out != expected {
+					errs <- out
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for out := range errs {
+			t.Fatalf("%s: expected concurrent parse to be:\n%s\ngot:\n%s", name, expected, out)
+		}
+
+		if stats := test.parser.Stats(); stats.LinesRead%33 != 0 {
+			t.Fatalf("%s: expected lines read to be a multiple of 33, got %d", name, stats.LinesRead)
+		}
+	}
+}
@@ -7,8 +7,62 @@ import (
 
 // Result type for each extracted JSON data and associated parse errors
 type Result struct {
-	Data   []byte
-	Errors []error
+	Data    []byte
+	Errors  []error
+	Meta    Meta
+	Kind    ResultKind
+	Summary *Summary // Only set for SummaryResult
+
+	values []typedValue // Values set in Data when parsed with TypedValues, decoded by Unmarshal
+}
+
+// typedValue is a value set in the Result.Data with the Value that parsed it, nil for raw values
+type typedValue struct {
+	path  []string
+	value *Value
+	data  interface{}
+}
+
+// ResultKind is the kind of a Result
+type ResultKind int
+
+const (
+	// RecordResult is a parsed record
+	RecordResult ResultKind = iota
+	// UnmatchedResult is an unmatched line with its position and raw text in Meta
+	UnmatchedResult
+	// SummaryResult is the summary of the lines read, sent at the end of the parse
+	SummaryResult
+)
+
+// Summary counts the lines read in a parse. Blank lines are only counted in Lines
+type Summary struct {
+	Lines     int64 // Lines read
+	Matched   int64 // Lines matched by the parser
+	Skipped   int64 // Lines skipped between SkipTag and ContinueTag
+	Unmatched int64 // Lines not matched by the parser
+}
+
+// Meta is the position in the input of the lines that made up a Result
+type Meta struct {
+	FirstLine int    // 1 based number of the first line
+	LastLine  int    // 1 based number of the last line
+	Start     int64  // Byte offset of the first line
+	End       int64  // Byte offset after the last line
+	Raw       []byte // Raw text of the lines, only set with the RawText option
+}
+
+// MarshalJSON encodes the metadata as a JSON object with snake case keys
+func (m Meta) MarshalJSON() (data []byte, err error) {
+	data = newJSON()
+	data, _ = jsonSet(data, m.FirstLine, "first_line")
+	data, _ = jsonSet(data, m.LastLine, "last_line")
+	data, _ = jsonSet(data, m.Start, "start")
+	data, _ = jsonSet(data, m.End, "end")
+	if m.Raw != nil {
+		data, err = jsonSet(data, m.Raw, "raw")
+	}
+	return data, err
 }
 
 // DataParser interface
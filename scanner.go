@@ -0,0 +1,69 @@
+package rexon
+
+import (
+	"bufio"
+	"io"
+)
+
+// lineScanner is a line bufio.Scanner that tracks the line number and offset
+type lineScanner struct {
+	*bufio.Scanner
+	line     int
+	offset   int64
+	consumed int64
+}
+
+func newLineScanner(r io.Reader) (s *lineScanner) {
+	s = &lineScanner{}
+	s.Scanner = bufio.NewScanner(r)
+	s.Scanner.Split(s.split)
+	return s
+}
+
+// Scan advances to the next line
+func (s *lineScanner) Scan() (ok bool) {
+	if ok = s.Scanner.Scan(); ok {
+		s.line++
+	}
+	return ok
+}
+
+// Line returns the 1 based number of the current line
+func (s *lineScanner) Line() (line int) {
+	return s.line
+}
+
+// Offset returns the byte offset of the current line in the input
+func (s *lineScanner) Offset() (offset int64) {
+	return s.offset
+}
+
+// End returns the byte offset after the current line in the input
+func (s *lineScanner) End() (offset int64) {
+	return s.consumed
+}
+
+// meta extends the metadata with the current line
+func (s *lineScanner) meta(m *Meta, line []byte, raw bool) {
+	if m.FirstLine == 0 {
+		m.FirstLine = s.line
+		m.Start = s.offset
+	} else if raw {
+		m.Raw = append(m.Raw, '\n')
+	}
+	m.LastLine = s.line
+	m.End = s.consumed
+
+	if raw {
+		m.Raw = append(m.Raw, line...)
+	}
+}
+
+func (s *lineScanner) split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	advance, token, err = bufio.ScanLines(data, atEOF)
+	if token != nil {
+		s.offset = s.consumed
+	}
+	s.consumed += int64(advance)
+	return advance, token, err
+}
@@ -0,0 +1,35 @@
+package rexon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineScanner(t *testing.T) {
+	s := newLineScanner(strings.NewReader("first\r\n\nthird\nlast"))
+
+	expected := []struct {
+		line   int
+		offset int64
+		text   string
+	}{
+		{1, 0, "first"},
+		{2, 7, ""},
+		{3, 8, "third"},
+		{4, 14, "last"},
+	}
+
+	for _, e := range expected {
+		if !s.Scan() {
+			t.Fatalf("expected line %d", e.line)
+		}
+
+		if s.Line() != e.line || s.Offset() != e.offset || s.Text() != e.text {
+			t.Fatalf("expected %d/%d/%q, got %d/%d/%q", e.line, e.offset, e.text, s.Line(), s.Offset(), s.Text())
+		}
+	}
+
+	if s.Scan() {
+		t.Fatalf("unexpected line %d", s.Line())
+	}
+}
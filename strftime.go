@@ -0,0 +1,135 @@
+package rexon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// strftimeDirectives maps the strftime directives to Go time layouts
+	strftimeDirectives = map[byte]string{
+		'a': "Mon",
+		'A': "Monday",
+		'b': "Jan",
+		'B': "January",
+		'c': "Mon Jan _2 15:04:05 2006",
+		'd': "02",
+		'D': "01/02/06",
+		'e': "_2",
+		'F': "2006-01-02",
+		'h': "Jan",
+		'H': "15",
+		'I': "03",
+		'j': "002",
+		'm': "01",
+		'M': "04",
+		'n': "\n",
+		'p': "PM",
+		'P': "pm",
+		'r': "03:04:05 PM",
+		'R': "15:04",
+		'S': "05",
+		't': "\t",
+		'T': "15:04:05",
+		'x': "01/02/06",
+		'X': "15:04:05",
+		'y': "06",
+		'Y': "2006",
+		'z': "-0700",
+		'Z': "MST",
+	}
+
+	// layoutReference renders every Go layout element differently from the element itself
+	layoutReference = time.Date(2019, 11, 13, 9, 34, 56, 789000000, time.FixedZone("XYZ", 5*3600+1800))
+)
+
+// isStrftime reports whether the format has strftime directives
+func isStrftime(format string) (ok bool) {
+	return strings.IndexByte(format, '%') >= 0
+}
+
+// strftimeLayout translates a strftime format into a Go time layout for parsing or output.
+// Fractional seconds are supported with %f after a dot or comma, parsing 1 to 9 digits
+// and formatting 6 zero padded digits, and %:z is the offset with a colon
+func strftimeLayout(format string, output bool) (layout string, err error) {
+	var b strings.Builder
+	var literal strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			literal.WriteByte(format[i])
+			continue
+		}
+
+		if i+1 == len(format) {
+			return "", fmt.Errorf("%w, incomplete directive in: %s", ErrUnsupportedFormat, format)
+		}
+
+		if literal.Len() > 0 {
+			if err = writeLiteral(&b, literal.String(), format); err != nil {
+				return "", err
+			}
+			literal.Reset()
+		}
+
+		i++
+		switch directive := format[i]; {
+		case directive == 'f':
+			layout := b.String()
+			if layout == "" || (layout[len(layout)-1] != '.' && layout[len(layout)-1] != ',') {
+				return "", fmt.Errorf("%w, %%f must follow a dot or comma in: %s", ErrUnsupportedFormat, format)
+			}
+			if output {
+				b.WriteString("000000")
+			} else {
+				b.WriteString("999999999")
+			}
+
+		case directive == ':' && i+1 < len(format) && format[i+1] == 'z':
+			i++
+			b.WriteString("-07:00")
+
+		case directive == '%':
+			literal.WriteByte('%')
+
+		default:
+			elem, ok := strftimeDirectives[directive]
+			if !ok {
+				return "", fmt.Errorf("%w, directive %%%c in: %s", ErrUnsupportedFormat, directive, format)
+			}
+			b.WriteString(elem)
+		}
+	}
+
+	if literal.Len() > 0 {
+		if err = writeLiteral(&b, literal.String(), format); err != nil {
+			return "", err
+		}
+	}
+
+	return b.String(), nil
+}
+
+// writeLiteral writes the literal text of a strftime format,
+// that must not be mistaken for Go layout elements
+func writeLiteral(b *strings.Builder, literal, format string) (err error) {
+	if isLayout(literal) {
+		return fmt.Errorf("%w, literal %q is a time layout element in: %s", ErrUnsupportedFormat, literal, format)
+	}
+	b.WriteString(literal)
+	return nil
+}
+
+// isLayout reports whether the text has Go time layout elements
+func isLayout(text string) (ok bool) {
+	return layoutReference.Format(text) != text
+}
+
+// timeLayout returns the Go layout of a strftime format for parsing or output, or the format itself
+func timeLayout(format string, output bool) (layout string, err error) {
+	if isStrftime(format) {
+		return strftimeLayout(format, output)
+	}
+	return format, nil
+}
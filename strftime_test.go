@@ -0,0 +1,96 @@
+package rexon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrftimeLayout(t *testing.T) {
+	tests := []struct {
+		format string
+		layout string
+	}{
+		{"%Y-%m-%d %H:%M:%S", "2006-01-02 15:04:05"},
+		{"%d/%b/%Y:%T %z", "02/Jan/2006:15:04:05 -0700"},
+		{"%a %b %e %H:%M:%S %Z %Y", "Mon Jan _2 15:04:05 MST 2006"},
+		{"%FT%T.%f%:z", "2006-01-02T15:04:05.000000-07:00"},
+		{"%I:%M %p, %A %B %d %y", "03:04 PM, Monday January 02 06"},
+		{"%j%%", "002%"},
+		{"2006-01-02", "2006-01-02"},
+	}
+
+	for _, test := range tests {
+		layout, err := timeLayout(test.format, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if layout != test.layout {
+			t.Fatalf("expected layout %q for %s, got: %q", test.layout, test.format, layout)
+		}
+	}
+
+	layout, err := timeLayout("%FT%T.%f", false)
+	if err != nil || layout != "2006-01-02T15:04:05.999999999" {
+		t.Fatalf("expected the parse layout 2006-01-02T15:04:05.999999999, got: %q, %v", layout, err)
+	}
+
+	for _, format := range []string{"%Y-%Q", "%Y%", "%H:%M:%S%f", "%Y 1st %j", "%H Mon"} {
+		if _, err := timeLayout(format, false); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Fatalf("expected %s for %s, got: %v", ErrUnsupportedFormat, format, err)
+		}
+	}
+}
+
+func TestValueParseTimeStrftime(t *testing.T) {
+	tests := []struct {
+		from     string
+		to       string
+		input    string
+		expected interface{}
+	}{
+		{"%Y-%m-%d %H:%M:%S", "rfc3339", "2018-12-25 15:04:05", "2018-12-25T15:04:05Z"},
+		{"%d/%b/%Y:%H:%M:%S %z", "%Y-%m-%dT%H:%M:%S%z", "25/Dec/2018:15:04:05 +0100", "2018-12-25T15:04:05+0100"},
+		{"%Y-%m-%d %H:%M:%S.%f", "%H:%M:%S,%f", "2018-12-25 15:04:05.123456", "15:04:05,123456"},
+		{"%Y-%m-%d %H:%M:%S,%f", "rfc3339nano", "2026-10-16 09:12:01,123", "2026-10-16T09:12:01.123Z"},
+		{"%Y-%m-%d %H:%M:%S,%f", "rfc3339nano", "2026-10-16 09:12:01,123456789", "2026-10-16T09:12:01.123456789Z"},
+		{"%Y-%m-%d %H:%M:%S.%f", "%S.%f", "2026-10-16 09:12:01.5", "01.500000"},
+		{"2006-01-02 15:04:05", "Mon Jan _2 2006", "2018-12-25 15:04:05", "Tue Dec 25 2018"},
+		{"2006-01-02 15:04:05", "Unix", "2018-12-25 15:04:05", int64(1545750245)},
+	}
+
+	for _, test := range tests {
+		v, err := NewValue("time", Time, FromFormat(test.from), ToFormat(test.to))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value, _, err := v.Parse([]byte(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Fatalf("expected %s for %s -> %s, got: %v", test.expected, test.from, test.to, value)
+		}
+	}
+
+	v, err := NewValue("time", Time, FromFormats("%d/%m/%Y", "%Y-%m-%d"), ToFormat("%F"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _, err := v.Parse([]byte("2018-12-25"))
+	if err != nil || value != "2018-12-25" {
+		t.Fatalf("expected 2018-12-25, got: %v, %v", value, err)
+	}
+
+	v, err = NewValue("time", Time, FromFormat("%F"), ToFormat("days"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = v.Parse([]byte("2018-12-25")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected %s, got: %v", ErrUnsupportedFormat, err)
+	}
+
+	if _, err = NewValue("time", Time, FromFormat("%Q")); err == nil {
+		t.Fatal("expected error for an unsupported directive")
+	}
+}
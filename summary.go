@@ -0,0 +1,80 @@
+package rexon
+
+import (
+	"context"
+)
+
+// ReportUnmatched sets the parser to send the lines not matched by the LineRegex, Value regexps
+// or StartTag, and the data lines before a table header, as UnmatchedResult results.
+func ReportUnmatched() (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.reportUnmatched = true
+		return nil
+	}
+}
+
+// UnmatchedChannel sets the parser to send the unmatched lines to the given channel instead of
+// the results. The channel is not closed by the parser and must be read while parsing.
+func UnmatchedChannel(lines chan<- Meta) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.unmatchedCh = lines
+		return nil
+	}
+}
+
+// ReportSummary sets the parser to send a SummaryResult with the count
+// of lines read, matched, skipped and unmatched at the end of the parse
+func ReportSummary() (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.reportSummary = true
+		return nil
+	}
+}
+
+// unmatched counts and reports an unmatched line. Reports false if parsing must stop
+func (p *Parser) unmatched(ctx context.Context, emit emitFunc, track *tracker,
+	scanner *lineScanner, line []byte) (ok bool) {
+
+	track.unmatched(scanner.Line())
+	if !p.reportUnmatched && p.unmatchedCh == nil {
+		return true
+	}
+
+	var meta Meta
+	scanner.meta(&meta, line, true)
+	return p.sendUnmatched(ctx, emit, meta)
+}
+
+// sendUnmatched sends the unmatched lines to the unmatched channel or results
+func (p *Parser) sendUnmatched(ctx context.Context, emit emitFunc, meta Meta) (ok bool) {
+	if p.unmatchedCh == nil {
+		return emit(Result{Kind: UnmatchedResult, Meta: meta})
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case p.unmatchedCh <- meta:
+		return true
+	}
+}
+
+// sendScanError sends the error that stopped reading the input, as a line longer than
+// the scanner buffer, positioned after the last line read. Reports false if parsing must stop
+func (p *Parser) sendScanError(ctx context.Context, track *tracker, scanner *lineScanner, emit emitFunc) (ok bool) {
+	err := scanner.Err()
+	if err == nil {
+		return true
+	}
+
+	result := Result{}
+	result.Errors = append(result.Errors, lineError(err, "", scanner.Line()+1, scanner.End()))
+	return p.send(ctx, track, result, emit)
+}
+
+// sendSummary sends the summary if enabled
+func (p *Parser) sendSummary(ctx context.Context, emit emitFunc, summary Summary) {
+	if p.reportSummary {
+		emit(Result{Kind: SummaryResult, Summary: &summary})
+	}
+}
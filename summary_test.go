@@ -0,0 +1,122 @@
+package rexon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+var dataUnmatched = []byte(`id 1
+garbage
+# skip
+id 9
+# continue
+
+id 2
+more garbage`)
+
+func TestParserUnmatched(t *testing.T) {
+	values := []*Value{
+		MustNewValue("id", Integer)}
+
+	p, err := NewParser(values, LineRegex(`^id (\d+)`), SkipTag(`^# skip`), ContinueTag(`^# continue`),
+		ReportUnmatched(), ReportSummary())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records int
+	var unmatched []Meta
+	var summary *Summary
+	for d := range p.ParseBytes(context.Background(), dataUnmatched) {
+		switch d.Kind {
+		case RecordResult:
+			records++
+		case UnmatchedResult:
+			unmatched = append(unmatched, d.Meta)
+		case SummaryResult:
+			summary = d.Summary
+		}
+	}
+
+	if records != 2 {
+		t.Fatalf("expected 2 records, got %d", records)
+	}
+
+	if len(unmatched) != 3 || unmatched[0].FirstLine != 2 || string(unmatched[0].Raw) != "garbage" ||
+		unmatched[1].FirstLine != 5 || unmatched[2].FirstLine != 8 {
+		t.Fatalf("unexpected unmatched lines: %#v", unmatched)
+	}
+
+	expected := Summary{Lines: 8, Matched: 2, Skipped: 2, Unmatched: 3}
+	if summary == nil || *summary != expected {
+		t.Fatalf("expected summary %#v, got %#v", expected, summary)
+	}
+}
+
+func TestParserUnmatchedChannel(t *testing.T) {
+	values := []*Value{
+		MustNewValue("id", Integer, ValueRegex(`^id (\d+)`))}
+
+	lines := make(chan Meta, 10)
+	p, err := NewParser(values, StartTag(`^id`), UnmatchedChannel(lines))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for d := range p.ParseBytes(context.Background(), dataUnmatched) {
+		if d.Kind != RecordResult {
+			t.Fatalf("unexpected result kind %d", d.Kind)
+		}
+	}
+
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 unmatched lines, got %d", len(lines))
+	}
+}
+
+func TestParserScanError(t *testing.T) {
+	data := []byte("id 1\nid 2\nid " + string(bytes.Repeat([]byte("9"), bufio.MaxScanTokenSize)) + "\nid 3\n")
+
+	values := []*Value{
+		MustNewValue("id", Integer)}
+
+	parsers := map[string][]ParserOpt{
+		"line":   {LineRegex(`id (\d+)`)},
+		"set":    {StartTag(`^id`)},
+		"table":  {Table(), Headerless()},
+		"logfmt": {Logfmt()},
+	}
+
+	for mode, opts := range parsers {
+		p, err := NewParser(values, append(opts, ReportSummary())...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var results []Result
+		for result := range p.ParseBytes(context.Background(), data) {
+			results = append(results, result)
+		}
+
+		if len(results) < 2 || results[len(results)-1].Kind != SummaryResult {
+			t.Fatalf("%s: expected the summary as the last result, got: %+v", mode, results)
+		}
+
+		last := results[len(results)-2]
+		if len(last.Errors) != 1 || !errors.Is(last.Errors[0], bufio.ErrTooLong) {
+			t.Fatalf("%s: expected %s before the summary, got: %v", mode, bufio.ErrTooLong, last.Errors)
+		}
+
+		var perr *ParseError
+		if !errors.As(last.Errors[0], &perr) || perr.Line != 3 || perr.Offset != 10 {
+			t.Fatalf("%s: expected a ParseError at line 3 offset 10, got: %#v", mode, last.Errors[0])
+		}
+
+		if summary := results[len(results)-1].Summary; summary.Lines != 2 {
+			t.Fatalf("%s: expected 2 lines read, got: %d", mode, summary.Lines)
+		}
+	}
+}
@@ -0,0 +1,511 @@
+package rexon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// column is a table column bound to an optional Value
+type column struct {
+	name  string
+	path  []string
+	value *Value
+	start int // Start offset for fixed width columns
+	end   int // End offset for fixed width columns, 0 for the end of line
+}
+
+// ColumnSpec specifies a fixed width column from the Start to the End offset.
+// An End of 0 extends the column to the end of line. When Name is not set
+// the column is bound to the Value in the same position
+type ColumnSpec struct {
+	Name  string `json:"name,omitempty" yaml:"name,omitempty"`
+	Start int    `json:"start" yaml:"start"`
+	End   int    `json:"end,omitempty" yaml:"end,omitempty"`
+}
+
+// Table sets the parser to work in table mode, where the header line defines the column names
+// and each following line is parsed into a document. Columns are split by spaces and the last
+// column takes the remaining of the line, so it can contain spaces.
+// Columns are parsed by the Value with the same name or as strings when there is none.
+func Table() (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.table = true
+		return nil
+	}
+}
+
+// HeaderTag sets a regexp that matches the table header lines. Columns are taken from the last
+// header line before the data rows, so repeated and multi line headers are skipped.
+// Without a HeaderTag the first line is the header and all lines equal to it are skipped.
+func HeaderTag(expr string) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		regex, err := compileRegex(expr)
+		p.headerTag = regex
+		return err
+	}
+}
+
+// ColumnNames sets the table column names instead of deriving them from the header.
+// Without a HeaderTag all lines are parsed as data rows.
+func ColumnNames(names ...string) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		if len(names) == 0 {
+			return fmt.Errorf("empty column names")
+		}
+		p.columnNames = names
+		return nil
+	}
+}
+
+// Columns sets the parser to work in fixed width table mode using the specified column offsets.
+// Column values are trimmed of spaces and lines are not split by spaces, so values can contain them.
+// Without a HeaderTag all lines are parsed as data rows.
+func Columns(specs []ColumnSpec) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		if len(specs) == 0 {
+			return fmt.Errorf("empty column specs")
+		}
+
+		for i, spec := range specs {
+			if spec.Start < 0 || spec.End < 0 || (spec.End != 0 && spec.End <= spec.Start) {
+				return fmt.Errorf("invalid column %d offsets: %d-%d", i, spec.Start, spec.End)
+			}
+		}
+
+		p.table = true
+		p.fixedWidth = true
+		p.columnSpecs = specs
+		return nil
+	}
+}
+
+// FixedWidth sets the parser to work in fixed width table mode, where the column offsets are
+// inferred from the start of each header field. It is meant for left aligned columns with
+// values that contain spaces, TrimSpaces must not be used as it changes the offsets.
+func FixedWidth() (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.table = true
+		p.fixedWidth = true
+		return nil
+	}
+}
+
+// RuneOffsets sets fixed width column offsets to be counted in runes instead of bytes
+func RuneOffsets() (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.runes = true
+		return nil
+	}
+}
+
+// ColumnRename renames a column derived from the table header.
+// The header can be either the header text or the derived column name.
+func ColumnRename(header, name string) (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		if p.renames == nil {
+			p.renames = map[string]string{}
+		}
+		p.renames[header] = name
+		return nil
+	}
+}
+
+func (p *Parser) parseRows(ctx context.Context, data io.Reader, emit emitFunc) {
+	var skip bool
+	var checked bool
+	var merge bool
+	var line []byte
+	var header []byte
+	var fields [][]byte
+	var columns []column
+	var result Result
+	var delimited *delimitedSplitter
+	scanner := newLineScanner(data)
+	track := p.newTracker()
+
+	if p.delimiter != nil {
+		delimited = &delimitedSplitter{sep: p.delimiter, escape: p.escape}
+	}
+
+	if p.columnNames != nil && p.headerTag == nil {
+		columns = p.tableColumns(p.columnNames)
+	}
+
+	for scanner.Scan() {
+		track.read(scanner.Line())
+		line = scanner.Bytes()
+		if p.trimSpaces {
+			line = bytes.TrimSpace(line)
+		}
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		if p.stopTag != nil && p.stopTag.Match(line) {
+			track.stopped(scanner.Line())
+			break
+		}
+
+		if p.skipLine(line, &skip) {
+			track.skipped(scanner.Line())
+			continue
+		}
+
+		// Handle header lines
+		if p.headerTag != nil {
+			if p.headerTag.Match(line) {
+				track.matched(scanner.Line())
+				if p.columnNames == nil {
+					header = append(header[:0], line...)
+					columns = p.headerColumns(header, delimited, 0)
+					checked = false
+					merge = !p.fixedWidth && delimited == nil
+				} else if columns == nil {
+					columns = p.tableColumns(p.columnNames)
+				}
+				continue
+			}
+		} else if p.columnNames == nil {
+			if columns == nil {
+				track.matched(scanner.Line())
+				header = append(header[:0], line...)
+				columns = p.headerColumns(header, delimited, 0)
+				merge = !p.fixedWidth && delimited == nil
+				continue
+			}
+			if bytes.Equal(line, header) {
+				track.matched(scanner.Line())
+				continue
+			}
+		}
+
+		// Ignore data before the header
+		if columns == nil {
+			if !p.unmatched(ctx, emit, track, scanner, line) {
+				return
+			}
+			continue
+		}
+		track.matched(scanner.Line())
+
+		var err error
+		switch {
+		case p.fixedWidth:
+			fields = sliceColumns(line, columns, p.runes, fields[:0])
+		case delimited != nil:
+			fields, err = delimited.split(line, fields[:0])
+		default:
+			fields = splitFields(line, len(columns), fields[:0])
+		}
+
+		// Merge the trailing words of multi word headers as "Mounted on"
+		// into the last column when the first row has less fields
+		if merge {
+			merge = false
+			if len(fields) > 0 && len(fields) < len(columns) {
+				columns = p.headerColumns(header, nil, len(fields))
+			}
+		}
+
+		// Check that every value has a column once for each header
+		if !checked {
+			checked = true
+			if result = p.checkColumns(columns, scanner); result.Errors != nil {
+				if !p.send(ctx, track, result, emit) {
+					return
+				}
+			}
+		}
+
+		result = p.parseRow(columns, line, fields, scanner)
+		scanner.meta(&result.Meta, line, p.rawText)
+		if err != nil {
+			result.Errors = append(result.Errors, rowError(err, line, scanner.Line(), scanner.Offset()))
+		}
+
+		if !p.send(ctx, track, result, emit) {
+			return
+		}
+	}
+
+	if !p.sendScanError(ctx, track, scanner, emit) {
+		return
+	}
+
+	p.sendSummary(ctx, emit, track.summary)
+}
+
+// parseRow parses the row fields of the line into a document
+func (p *Parser) parseRow(columns []column, line []byte, fields [][]byte, scanner *lineScanner) (result Result) {
+	result.Data = newJSON()
+
+	for i := range fields {
+		if i == len(columns) {
+			break
+		}
+
+		var value interface{} = fields[i]
+
+		if columns[i].value != nil {
+			v, _, err := columns[i].value.Parse(fields[i])
+			if err != nil {
+				result.Errors = append(result.Errors,
+					lineError(err, columns[i].name, scanner.Line(), scanner.Offset()))
+			}
+			value = v
+		}
+
+		p.set(&result, columns[i].value, value, columns[i].path)
+	}
+
+	if len(fields) != len(columns) {
+		err := fmt.Errorf("%w, row has %d fields for %d columns", ErrCaptureCount, len(fields), len(columns))
+		result.Errors = append(result.Errors, rowError(err, line, scanner.Line(), scanner.Offset()))
+	}
+
+	return result
+}
+
+// checkColumns reports values without a matching column
+func (p *Parser) checkColumns(columns []column, scanner *lineScanner) (result Result) {
+	for vp := range p.values {
+		var found bool
+		for c := range columns {
+			if columns[c].value == p.values[vp] {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			err := fmt.Errorf("%w, table has no column for value", ErrNoMatch)
+			perr := lineError(err, p.values[vp].name, scanner.Line(), scanner.Offset())
+			perr.Type = p.values[vp].valueType
+			result.Errors = append(result.Errors, perr)
+		}
+	}
+	return result
+}
+
+// tableColumns binds the column names to values
+func (p *Parser) tableColumns(names []string) (columns []column) {
+	columns = make([]column, len(names))
+	for i, name := range names {
+		columns[i].name = name
+		if v, ok := p.value(name); ok {
+			columns[i].value = v
+			columns[i].path = v.path
+			continue
+		}
+
+		path, err := jsonPath(name)
+		if err != nil {
+			path = []string{name}
+		}
+		columns[i].path = path
+	}
+
+	for i := range p.columnSpecs {
+		columns[i].start = p.columnSpecs[i].Start
+		columns[i].end = p.columnSpecs[i].End
+	}
+
+	return columns
+}
+
+// headerColumns derives the columns from a header line.
+// With a width greater than zero the header words after it are merged into the last column
+func (p *Parser) headerColumns(line []byte, delimited *delimitedSplitter, width int) (columns []column) {
+	var fields [][]byte
+	switch {
+	case delimited != nil:
+		fields, _ = delimited.split(line, nil)
+	case width > 0:
+		fields = splitFields(line, width, nil)
+	default:
+		fields = bytes.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == '\t' })
+	}
+
+	columns = p.tableColumns(p.headerNames(fields))
+	if !p.fixedWidth {
+		return columns
+	}
+
+	// Offsets are counted in bytes or runes
+	var offset, field int
+	for i := 0; i < len(line) && field < len(columns); offset++ {
+		if !isSpace(line[i]) && (i == 0 || isSpace(line[i-1])) {
+			columns[field].start = offset
+			if field > 0 {
+				columns[field-1].end = offset
+			}
+			field++
+		}
+
+		size := 1
+		if p.runes {
+			_, size = utf8.DecodeRune(line[i:])
+		}
+		i += size
+	}
+
+	return columns
+}
+
+// Headerless sets the table to have no header line, so columns are bound to the values
+// by position. With a HeaderTag the header lines are skipped.
+func Headerless() (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.headerless = true
+		return nil
+	}
+}
+
+// specNames returns the column spec names or the name of the value in the same position
+func (p *Parser) specNames() (names []string, err error) {
+	if p.columnNames != nil {
+		return nil, fmt.Errorf("column names cannot be used with column specs or headerless tables")
+	}
+
+	if p.headerless && p.columnSpecs == nil {
+		if len(p.values) == 0 {
+			return nil, fmt.Errorf("headerless table without values")
+		}
+
+		names = make([]string, len(p.values))
+		for i := range p.values {
+			names[i] = p.values[i].name
+		}
+		return names, nil
+	}
+
+	names = make([]string, len(p.columnSpecs))
+	for i, spec := range p.columnSpecs {
+		switch {
+		case spec.Name != "":
+			names[i] = spec.Name
+		case i < len(p.values):
+			names[i] = p.values[i].name
+		default:
+			return nil, fmt.Errorf("column %d has no name and no value", i)
+		}
+	}
+
+	return names, nil
+}
+
+// headerNames derives the column names from the header fields
+func (p *Parser) headerNames(fields [][]byte) (names []string) {
+	names = make([]string, len(fields))
+	seen := make(map[string]int, len(fields))
+
+	for i := range fields {
+		name, ok := p.renames[string(fields[i])]
+		if !ok {
+			name = columnName(fields[i])
+			if rename, ok := p.renames[name]; ok {
+				name = rename
+			}
+		}
+
+		if name == "" {
+			name = "column_" + strconv.Itoa(i+1)
+		}
+
+		seen[name]++
+		if seen[name] > 1 {
+			name = name + "_" + strconv.Itoa(seen[name])
+		}
+
+		names[i] = name
+	}
+
+	return names
+}
+
+// columnName normalizes a header field into a lower case name where
+// any sequence of non alphanumeric characters is replaced by an underscore
+func columnName(field []byte) (name string) {
+	buf := make([]byte, 0, len(field))
+	for _, c := range bytes.ToLower(field) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			buf = append(buf, c)
+			continue
+		}
+
+		if len(buf) > 0 && buf[len(buf)-1] != '_' {
+			buf = append(buf, '_')
+		}
+	}
+
+	return string(bytes.TrimRight(buf, "_"))
+}
+
+// splitFields splits the line in up to n space separated fields,
+// where the last field takes the remaining of the line
+func splitFields(line []byte, n int, fields [][]byte) [][]byte {
+	line = bytes.TrimSpace(line)
+
+	for len(line) > 0 && len(fields) < n-1 {
+		end := bytes.IndexAny(line, " \t")
+		if end < 0 {
+			break
+		}
+		fields = append(fields, line[:end])
+		line = bytes.TrimLeft(line[end:], " \t")
+	}
+
+	if len(line) > 0 {
+		fields = append(fields, line)
+	}
+
+	return fields
+}
+
+// sliceColumns slices the line in the fixed width columns
+func sliceColumns(line []byte, columns []column, runes bool, fields [][]byte) [][]byte {
+	for i := range columns {
+		start, end := columns[i].start, columns[i].end
+		if runes {
+			start = runeOffset(line, start)
+			if end > 0 {
+				end = runeOffset(line, end)
+			}
+		}
+
+		if start > len(line) {
+			start = len(line)
+		}
+
+		if end <= 0 || end > len(line) {
+			end = len(line)
+		}
+
+		fields = append(fields, bytes.TrimSpace(line[start:end]))
+	}
+
+	return fields
+}
+
+// runeOffset returns the byte offset for the given rune offset in b
+func runeOffset(b []byte, n int) (offset int) {
+	for n > 0 && offset < len(b) {
+		_, size := utf8.DecodeRune(b[offset:])
+		offset += size
+		n--
+	}
+
+	if n > 0 {
+		return len(b) + n
+	}
+	return offset
+}
+
+func isSpace(c byte) (ok bool) {
+	return c == ' ' || c == '\t'
+}
@@ -0,0 +1,264 @@
+package rexon
+
+import (
+	"context"
+	"testing"
+)
+
+var (
+	dataPs = []byte(`  PID TTY          TIME CMD
+    1 ?        00:00:02 /sbin/init splash
+  812 pts/0    00:00:00 bash
+ 1024 pts/0    00:00:00 ps -ef --forest`)
+
+	dataDf = []byte(`Filesystem     1024-blocks     Used Available Capacity Mounted on
+/dev/sda1         41152736 18744652  20294888      49% /
+tmpfs              8159204        0   8159204       0% /mnt/my data`)
+
+	dataVmstat = []byte(`procs -----------memory---------- ---swap-- -----io---- -system-- ------cpu-----
+ r  b   swpd   free   buff  cache   si   so    bi    bo   in   cs us sy id wa st
+ 1  0      0 812340 120440 992044    0    0     5    12   52  110  2  1 97  0  0
+procs -----------memory---------- ---swap-- -----io---- -system-- ------cpu-----
+ r  b   swpd   free   buff  cache   si   so    bi    bo   in   cs us sy id wa st
+ 0  0      0 812100 120440 992060    0    0     0     0   98  201  1  0 99  0  0`)
+)
+
+func TestParserTable(t *testing.T) {
+	values := []*Value{
+		MustNewValue("pid", Integer)}
+
+	p, err := NewParser(values, Table(), ColumnRename("CMD", "command"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"pid":1,"tty":"?","time":"00:00:02","command":"/sbin/init splash"}`,
+		`{"pid":812,"tty":"pts/0","time":"00:00:00","command":"bash"}`,
+		`{"pid":1024,"tty":"pts/0","time":"00:00:00","command":"ps -ef --forest"}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dataPs) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+
+	if count != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), count)
+	}
+}
+
+func TestParserTableColumnNames(t *testing.T) {
+	values := []*Value{
+		MustNewValue("blocks", DigitalUnit, FromFormat("kib"), Round(0)),
+		MustNewValue("capacity", Number, ValueRegex(`(\d+)%`))}
+
+	p, err := NewParser(values, Table(), HeaderTag(`^Filesystem`),
+		ColumnNames("filesystem", "blocks", "used", "available", "capacity", "mount"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"filesystem":"/dev/sda1","blocks":42140401664,"used":"18744652","available":"20294888","capacity":49,"mount":"/"}`,
+		`{"filesystem":"tmpfs","blocks":8355024896,"used":"0","available":"8159204","capacity":0,"mount":"/mnt/my data"}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dataDf) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+}
+
+func TestParserTableMultiWordHeader(t *testing.T) {
+	dfh := []byte(`Filesystem      Size  Used Avail Use% Mounted on
+udev            7.8G     0  7.8G   0% /dev
+tmpfs           1.6G  2.2M  1.6G   1% /run
+/dev/nvme0n1p2  468G  201G  244G  46% /
+/dev/nvme0n1p1  511M  6.1M  505M   2% /boot/efi`)
+
+	values := []*Value{
+		MustNewValue("use", Number, ValueRegex(`(\d+)%`))}
+
+	p, err := NewParser(values, Table(), ColumnRename("Mounted on", "mount"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"filesystem":"udev","size":"7.8G","used":"0","avail":"7.8G","use":0,"mount":"/dev"}`,
+		`{"filesystem":"tmpfs","size":"1.6G","used":"2.2M","avail":"1.6G","use":1,"mount":"/run"}`,
+		`{"filesystem":"/dev/nvme0n1p2","size":"468G","used":"201G","avail":"244G","use":46,"mount":"/"}`,
+		`{"filesystem":"/dev/nvme0n1p1","size":"511M","used":"6.1M","avail":"505M","use":2,"mount":"/boot/efi"}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dfh) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+	if count != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), count)
+	}
+
+	p, err = NewParser(nil, Table())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{
+		`{"filesystem":"/dev/sda1","1024_blocks":"41152736","used":"18744652","available":"20294888","capacity":"49%","mounted_on":"/"}`,
+		`{"filesystem":"tmpfs","1024_blocks":"8159204","used":"0","available":"8159204","capacity":"0%","mounted_on":"/mnt/my data"}`,
+	}
+
+	count = 0
+	for d := range p.ParseBytes(context.Background(), dataDf) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+	if count != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), count)
+	}
+}
+
+func TestParserTableRepeatedHeader(t *testing.T) {
+	values := []*Value{
+		MustNewValue("free", Integer),
+		MustNewValue("id", Integer)}
+
+	p, err := NewParser(values, Table(), HeaderTag(`^(procs|\s*r\s+b)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), dataVmstat) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		t.Logf("%s\n", d.Data)
+		count++
+	}
+
+	if count != 2 {
+		t.Fatalf("expected 2 results, got %d", count)
+	}
+}
+
+func TestParserTableErrors(t *testing.T) {
+	values := []*Value{
+		MustNewValue("pid", Integer),
+		MustNewValue("missing", String)}
+
+	p, err := NewParser(values, Table())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errors int
+	for d := range p.ParseBytes(context.Background(), []byte("PID TTY\n1 ?\n2\n")) {
+		errors += len(d.Errors)
+	}
+
+	if errors != 2 {
+		t.Fatalf("expected 2 errors, got %d", errors)
+	}
+
+	if _, err = NewParser(values, Table(), LineRegex(`(\d+)`)); err == nil {
+		t.Fatal("expected error for table mode with line regex")
+	}
+}
+
+func TestParserFixedWidth(t *testing.T) {
+	data := []byte(`name    STATE      DESCRIPTION
+hdisk0  Available  Virtual SCSI Disk Drive
+ent0    Defined    Logical Host Ethernet Port
+`)
+
+	p, err := NewParser(nil, FixedWidth())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"name":"hdisk0","state":"Available","description":"Virtual SCSI Disk Drive"}`,
+		`{"name":"ent0","state":"Defined","description":"Logical Host Ethernet Port"}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), data) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+}
+
+func TestParserColumns(t *testing.T) {
+	data := []byte(`sda    8:0  ça va    465.8G
+sda1   8:1  não sei  512M
+`)
+
+	values := []*Value{
+		MustNewValue("name", String),
+		MustNewValue("label", String),
+		MustNewValue("size", DigitalUnit, ToFormat("mb"), Round(1))}
+
+	p, err := NewParser(values, RuneOffsets(), Columns([]ColumnSpec{
+		{Start: 0, End: 7},
+		{Start: 12, End: 21},
+		{Start: 21},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"name":"sda","label":"ça va","size":465800}`,
+		`{"name":"sda1","label":"não sei","size":512}`,
+	}
+
+	var count int
+	for d := range p.ParseBytes(context.Background(), data) {
+		if d.Errors != nil {
+			t.Fatal(d.Errors)
+		}
+		if string(d.Data) != expected[count] {
+			t.Fatalf("expected %s, got %s", expected[count], d.Data)
+		}
+		count++
+	}
+
+	if _, err = NewParser(values, Columns([]ColumnSpec{{Start: 5, End: 2}})); err == nil {
+		t.Fatal("expected error for invalid column offsets")
+	}
+
+	if _, err = NewParser(nil, Columns([]ColumnSpec{{Start: 0}})); err == nil {
+		t.Fatal("expected error for column without name")
+	}
+}
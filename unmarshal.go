@@ -0,0 +1,402 @@
+package rexon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	// structFields caches the tagged fields of each struct type
+	structFields sync.Map
+)
+
+// field is a struct field tagged with the name of a Value
+type field struct {
+	name  string
+	path  []string
+	index []int
+	typ   reflect.Type
+}
+
+// TypedValues sets the parser to keep the parsed values in each record Result to be decoded by Unmarshal.
+// It is set by ParseInto, and costs an allocation for each record and each string value
+func TypedValues() (opt ParserOpt) {
+	return func(p *Parser) (err error) {
+		p.typedValues = true
+		return nil
+	}
+}
+
+// set sets the value at the Result.Data path and keeps it typed
+func (p *Parser) set(result *Result, v *Value, value interface{}, path []string) {
+	result.Data, _ = jsonSetPath(result.Data, value, path)
+	p.keep(result, v, value, path)
+}
+
+// keep keeps the typed value if the parser has TypedValues. Strings are copied
+// as they may refer to the scanner buffer that is reused by the next lines
+func (p *Parser) keep(result *Result, v *Value, value interface{}, path []string) {
+	if !p.typedValues {
+		return
+	}
+
+	switch data := value.(type) {
+	case string:
+		value = strings.Clone(data)
+	case []byte:
+		value = string(data)
+	}
+	result.values = append(result.values, typedValue{path: path, value: v, data: value})
+}
+
+// Unmarshal decodes the typed values of a record Result from a Parser with TypedValues into the struct pointed by v,
+// without decoding the Result.Data.
+// Fields are filled from the Value named in their tag as `rexon:"name"`, with dotted names
+// for Values set with Path, or from the column and key names of raw values. Missing and null values leave the field unchanged.
+//
+// Supported field types are integers, unsigned integers, floats, string, bool,
+// time.Time from Time values and time.Duration from Duration values, converted back from the Value ToFormat.
+// Values not matching the field type, as numbers that are not from a Duration value, are reported with an ErrTypeMismatch ParseError.
+func Unmarshal(result Result, v interface{}) (err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal destination must be a non nil struct pointer, got: %T", v)
+	}
+
+	fields, err := typeFields(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	if result.values == nil && len(result.Data) > len("{}") {
+		return fmt.Errorf("result without typed values, the parser must have TypedValues")
+	}
+
+	return decode(result.values, rv.Elem(), fields)
+}
+
+// ParseInto returns an iterator that parses raw data decoding each record into a T struct, as Unmarshal.
+// The parser is used with TypedValues regardless of its options.
+// Records with parse errors are yielded along with the joined errors, and the context error is yielded last if it is done.
+func ParseInto[T any](ctx context.Context, p *Parser, data io.Reader) (seq iter.Seq2[T, error]) {
+	return func(yield func(T, error) bool) {
+		var zero T
+		fields, err := typeFields(reflect.TypeOf(zero))
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		typed := *p
+		typed.typedValues = true
+
+		for result, err := range typed.ParseSeq(ctx, data) {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			if result.Kind != RecordResult {
+				continue
+			}
+
+			var value T
+			errs := result.Errors
+			if err = decode(result.values, reflect.ValueOf(&value).Elem(), fields); err != nil {
+				errs = append(errs[:len(errs):len(errs)],
+					lineError(err, "", result.Meta.FirstLine, result.Meta.Start))
+			}
+
+			if !yield(value, errors.Join(errs...)) {
+				return
+			}
+		}
+	}
+}
+
+// typeFields returns the tagged fields of the given struct type
+func typeFields(t reflect.Type) (fields []field, err error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unmarshal destination must be a struct, got: %v", t)
+	}
+
+	if cached, ok := structFields.Load(t); ok {
+		return cached.([]field), nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("rexon")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if f.PkgPath != "" {
+			return nil, fmt.Errorf("unexported field %s tagged as %s", f.Name, name)
+		}
+
+		if !supportedField(f.Type) {
+			return nil, fmt.Errorf("unsupported type %s for field %s", f.Type, f.Name)
+		}
+
+		path, err := jsonPath(name)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field{name: name, path: path, index: f.Index, typ: f.Type})
+	}
+
+	structFields.Store(t, fields)
+	return fields, nil
+}
+
+// supportedField reports whether the type can be decoded from a Value
+func supportedField(t reflect.Type) (ok bool) {
+	if t == timeType || t == durationType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// decode sets the struct fields from the typed values of a record
+func decode(values []typedValue, rv reflect.Value, fields []field) (err error) {
+	for _, f := range fields {
+		tv, ok := lookupValue(values, f.path)
+		if !ok || tv.data == nil {
+			continue
+		}
+
+		if err = setField(rv.FieldByIndex(f.index), tv); err != nil {
+			return &ParseError{Value: f.name, Input: fmt.Sprint(tv.data), Err: err}
+		}
+	}
+
+	return nil
+}
+
+// lookupValue returns the last value set at the path
+func lookupValue(values []typedValue, path []string) (tv typedValue, ok bool) {
+	for i := len(values) - 1; i >= 0; i-- {
+		if equalPath(values[i].path, path) {
+			return values[i], true
+		}
+	}
+	return tv, false
+}
+
+// equalPath reports whether the paths are equal
+func equalPath(a, b []string) (ok bool) {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// setField sets the field from a typed value
+func setField(fv reflect.Value, tv typedValue) (err error) {
+	switch fv.Type() {
+	case timeType:
+		t, err := valueTime(tv)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+
+	case durationType:
+		d, err := valueDuration(tv)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := toInt(tv.data)
+		if !ok {
+			return mismatch(fv, tv.data)
+		}
+		if fv.OverflowInt(n) {
+			return fmt.Errorf("%w, %v overflows %s", ErrTypeMismatch, tv.data, fv.Type())
+		}
+		fv.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := toUint(tv.data)
+		if !ok {
+			return mismatch(fv, tv.data)
+		}
+		if fv.OverflowUint(n) {
+			return fmt.Errorf("%w, %v overflows %s", ErrTypeMismatch, tv.data, fv.Type())
+		}
+		fv.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat(tv.data)
+		if !ok {
+			return mismatch(fv, tv.data)
+		}
+		if fv.OverflowFloat(f) {
+			return fmt.Errorf("%w, %v overflows %s", ErrTypeMismatch, tv.data, fv.Type())
+		}
+		fv.SetFloat(f)
+
+	case reflect.String:
+		s, ok := tv.data.(string)
+		if !ok {
+			return mismatch(fv, tv.data)
+		}
+		fv.SetString(s)
+
+	case reflect.Bool:
+		b, ok := tv.data.(bool)
+		if !ok {
+			return mismatch(fv, tv.data)
+		}
+		fv.SetBool(b)
+	}
+
+	return nil
+}
+
+// valueTime converts a Time value back from its ToFormat
+func valueTime(tv typedValue) (t time.Time, err error) {
+	if tv.value == nil || tv.value.valueType != Time {
+		return t, fmt.Errorf("%w, cannot decode a non time value %v into %s", ErrTypeMismatch, tv.data, timeType)
+	}
+
+	v := tv.value
+	loc := v.toLocation
+	if loc == nil {
+		loc = v.location
+	}
+
+	switch data := tv.data.(type) {
+	case int64:
+		switch v.toFormat {
+		case "unix":
+			return time.Unix(data, 0).In(loc), nil
+		case "unix_milli":
+			return time.UnixMilli(data).In(loc), nil
+		case "unix_micro":
+			return time.UnixMicro(data).In(loc), nil
+		case "unix_nano":
+			return time.Unix(0, data).In(loc), nil
+		}
+
+	case string:
+		layout := v.toLayout
+		switch v.toFormat {
+		case "rfc3339", "rfc3339nano", "string", "":
+			layout = time.RFC3339Nano
+		}
+		return time.ParseInLocation(layout, data, loc)
+	}
+
+	return t, fmt.Errorf("%w, cannot decode %v as %s into %s", ErrTypeMismatch, tv.data, v.toFormat, timeType)
+}
+
+// valueDuration converts a Duration value back from the unit of its ToFormat.
+// Numbers without a Duration value are ambiguous and not decoded
+func valueDuration(tv typedValue) (d time.Duration, err error) {
+	if tv.value == nil || tv.value.valueType != Duration {
+		return 0, fmt.Errorf("%w, cannot decode a non duration value %v into %s", ErrTypeMismatch, tv.data, durationType)
+	}
+
+	if s, ok := tv.data.(string); ok {
+		return time.ParseDuration(s)
+	}
+
+	var unit time.Duration
+	switch tv.value.toFormat {
+	case "nanoseconds", "nanosecond", "nano", "ns":
+		unit = time.Nanosecond
+	case "milliseconds", "millisecond", "milli", "ms":
+		unit = time.Millisecond
+	case "seconds", "second", "sec", "s":
+		unit = time.Second
+	case "minutes", "minute", "min", "m":
+		unit = time.Minute
+	case "hours", "hour", "h":
+		unit = time.Hour
+	}
+
+	f, ok := toFloat(tv.data)
+	if !ok || unit == 0 {
+		return 0, fmt.Errorf("%w, cannot decode %v as %s into %s", ErrTypeMismatch, tv.data, tv.value.toFormat, durationType)
+	}
+	if n, ok := tv.data.(int64); ok {
+		return time.Duration(n) * unit, nil
+	}
+	return time.Duration(math.Round(f * float64(unit))), nil
+}
+
+// toInt returns the typed value as an int64 if it is an integral number in range
+func toInt(data interface{}) (n int64, ok bool) {
+	switch data := data.(type) {
+	case int64:
+		return data, true
+	case uint64:
+		return int64(data), data <= math.MaxInt64
+	case float64:
+		return int64(data), data == math.Trunc(data) && data >= math.MinInt64 && data < math.MaxInt64
+	}
+	return 0, false
+}
+
+// toUint returns the typed value as an uint64 if it is a non negative integral number in range
+func toUint(data interface{}) (n uint64, ok bool) {
+	switch data := data.(type) {
+	case int64:
+		return uint64(data), data >= 0
+	case uint64:
+		return data, true
+	case float64:
+		return uint64(data), data == math.Trunc(data) && data >= 0 && data < math.MaxUint64
+	}
+	return 0, false
+}
+
+// toFloat returns the typed value as a float64 if it is a number
+func toFloat(data interface{}) (f float64, ok bool) {
+	switch data := data.(type) {
+	case int64:
+		return float64(data), true
+	case uint64:
+		return float64(data), true
+	case float64:
+		return data, true
+	}
+	return 0, false
+}
+
+// mismatch returns the error for a typed value that cannot be decoded into the field
+func mismatch(fv reflect.Value, data interface{}) (err error) {
+	return fmt.Errorf("%w, cannot decode %T %v into %s", ErrTypeMismatch, data, data, fv.Type())
+}
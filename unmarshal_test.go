@@ -0,0 +1,295 @@
+package rexon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type unmarshalDisk struct {
+	Major   int           `rexon:"maj"`
+	Minor   uint8         `rexon:"min"`
+	Device  string        `rexon:"device"`
+	Reads   float64       `rexon:"stats.reads"`
+	Active  bool          `rexon:"active"`
+	Updated time.Time     `rexon:"updated"`
+	Created time.Time     `rexon:"created"`
+	Uptime  time.Duration `rexon:"uptime"`
+	Wait    time.Duration `rexon:"wait"`
+	Latency time.Duration `rexon:"latency"`
+	Ignored string
+}
+
+// unmarshalResult returns the first record parsed from the line with a value for each field
+func unmarshalResult(t *testing.T, values []*Value, line string) (result Result) {
+	regex := strings.TrimSpace(strings.Repeat(`(\S+)\s*`, len(values)))
+	p, err := NewParser(values, LineRegex(regex), TypedValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for result, err := range p.ParseSeq(context.Background(), strings.NewReader(line)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Kind == RecordResult {
+			return result
+		}
+	}
+
+	t.Fatalf("expected a record for: %s", line)
+	return result
+}
+
+func TestUnmarshal(t *testing.T) {
+	values := []*Value{
+		MustNewValue("maj", Integer),
+		MustNewValue("min", Number),
+		MustNewValue("device", String),
+		MustNewValue("reads", Number, Path("stats", "reads")),
+		MustNewValue("active", Bool),
+		MustNewValue("updated", Time, FromFormat("unix"), ToFormat("unix_milli")),
+		MustNewValue("created", Time, FromFormat("%d/%m/%Y %H:%M"), ToFormat("%Y-%m-%dT%H:%M")),
+		MustNewValue("uptime", Duration),
+		MustNewValue("wait", Duration, ToFormat("seconds")),
+		MustNewValue("latency", Duration, ToFormat("ms")),
+		MustNewValue("other", String, Nullable())}
+
+	p, err := NewParser(values, LineRegex(`^(\d+) (\d+) (\S+) (\S+) (\S+) (\d+) (\S+ \S+) (\S+) (\S+) (\S+) (null)$`), TypedValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var disk unmarshalDisk
+	line := "8 1 sda\"1 193.5 true 1514905445 02/01/2018 15:04 1h30m 90 1500ms null\n"
+	for result, err := range p.ParseSeq(context.Background(), strings.NewReader(line)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Kind != RecordResult {
+			continue
+		}
+		if result.Errors != nil {
+			t.Fatal(result.Errors)
+		}
+		if err = Unmarshal(result, &disk); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := unmarshalDisk{
+		Major:   8,
+		Minor:   1,
+		Device:  `sda"1`,
+		Reads:   193.5,
+		Active:  true,
+		Updated: time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC),
+		Created: time.Date(2018, 1, 2, 15, 4, 0, 0, time.UTC),
+		Uptime:  90 * time.Minute,
+		Wait:    90 * time.Second,
+		Latency: 1500 * time.Millisecond,
+	}
+	if disk != expected {
+		t.Fatalf("expected %+v, got: %+v", expected, disk)
+	}
+
+	disk = unmarshalDisk{Device: "keep"}
+	result := unmarshalResult(t, []*Value{MustNewValue("maj", Number), MustNewValue("device", String, Nullable())}, "3 null")
+	if err := Unmarshal(result, &disk); err != nil {
+		t.Fatal(err)
+	}
+	if disk.Major != 3 || disk.Device != "keep" {
+		t.Fatalf("expected missing and null values to be ignored, got: %+v", disk)
+	}
+}
+
+func TestUnmarshalTable(t *testing.T) {
+	p, err := NewParser([]*Value{MustNewValue("maj", Number)}, Table(), TypedValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var disk unmarshalDisk
+	for result, err := range p.ParseSeq(context.Background(), strings.NewReader("maj device\n8 sda\n")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Kind != RecordResult {
+			continue
+		}
+		if err = Unmarshal(result, &disk); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if disk.Major != 8 || disk.Device != "sda" {
+		t.Fatalf("expected typed and raw columns decoded, got: %+v", disk)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	cases := []struct {
+		value *Value
+		input string
+		name  string
+	}{
+		{MustNewValue("maj", String), "8", "maj"},
+		{MustNewValue("maj", Number), "8.5", "maj"},
+		{MustNewValue("min", Number), "256", "min"},
+		{MustNewValue("min", Number), "-1", "min"},
+		{MustNewValue("device", Number), "8", "device"},
+		{MustNewValue("active", String), "true", "active"},
+		{MustNewValue("updated", Number), "1514905445", "updated"},
+		{MustNewValue("updated", String), "2018-01-02T15:04:05Z", "updated"},
+		{MustNewValue("wait", Number), "1500", "wait"},
+		{MustNewValue("wait", Integer), "1500", "wait"},
+		{MustNewValue("reads", String, Path("stats", "reads")), "1", "stats.reads"},
+	}
+
+	for _, c := range cases {
+		var disk unmarshalDisk
+		err := Unmarshal(unmarshalResult(t, []*Value{c.value}, c.input), &disk)
+		if !errors.Is(err, ErrTypeMismatch) {
+			t.Fatalf("expected %s for %s %s, got: %v", ErrTypeMismatch, c.name, c.input, err)
+		}
+
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Value != c.name {
+			t.Fatalf("expected a ParseError for %s, got: %#v", c.name, err)
+		}
+	}
+
+	var disk unmarshalDisk
+	if err := Unmarshal(Result{}, disk); err == nil {
+		t.Fatal("expected error for a non pointer destination")
+	}
+
+	p, err := NewParser([]*Value{MustNewValue("maj", Number)}, LineRegex(`(\d+)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for result := range p.ParseBytes(context.Background(), []byte("8\n")) {
+		if result.values != nil {
+			t.Fatalf("expected no typed values without TypedValues, got: %v", result.values)
+		}
+		if err := Unmarshal(result, &disk); err == nil {
+			t.Fatal("expected error for a result without typed values")
+		}
+	}
+
+	var invalid struct {
+		Values []int `rexon:"values"`
+	}
+	if err := Unmarshal(Result{}, &invalid); err == nil {
+		t.Fatal("expected error for an unsupported field type")
+	}
+}
+
+func TestUnmarshalRetained(t *testing.T) {
+	p, err := NewParser([]*Value{MustNewValue("name", String)}, LineRegex(`^(\S+)$`), TypedValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data bytes.Buffer
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&data, "name%06d\n", i)
+	}
+
+	var results []Result
+	for result := range p.Parse(context.Background(), &data) {
+		results = append(results, result)
+	}
+
+	if len(results) != 5000 {
+		t.Fatalf("expected 5000 records, got: %d", len(results))
+	}
+
+	for i, result := range results {
+		var record struct {
+			Name string `rexon:"name"`
+		}
+		if err := Unmarshal(result, &record); err != nil {
+			t.Fatal(err)
+		}
+		if expected := fmt.Sprintf("name%06d", i); record.Name != expected {
+			t.Fatalf("expected %s for result %d, got: %s", expected, i, record.Name)
+		}
+	}
+}
+
+func TestParseInto(t *testing.T) {
+	values := []*Value{
+		MustNewValue("maj", Number),
+		MustNewValue("min", Number),
+		MustNewValue("device", String)}
+
+	p, err := NewParser(values, LineRegex(`(\d+)\s+(\d+)\s+(.*?)\s+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var disks []unmarshalDisk
+	for disk, err := range ParseInto[unmarshalDisk](context.Background(), p, bytes.NewReader(dataLine)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		disks = append(disks, disk)
+	}
+
+	if len(disks) != 12 {
+		t.Fatalf("expected 12 records, got: %d", len(disks))
+	}
+	if disks[2].Major != 8 || disks[2].Minor != 1 || disks[2].Device != "sda1" {
+		t.Fatalf("unexpected record: %+v", disks[2])
+	}
+
+	values = []*Value{
+		MustNewValue("maj", Number),
+		MustNewValue("min", Number),
+		MustNewValue("device", Number)}
+
+	p, err = NewParser(values, LineRegex(`(\d+)\s+(\d+)\s+(.*?)\s+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for disk, err := range ParseInto[unmarshalDisk](context.Background(), p, bytes.NewReader(dataLine)) {
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Line != 1 {
+			t.Fatalf("expected a ParseError at line 1, got: %v", err)
+		}
+		if disk.Major != 2 {
+			t.Fatalf("expected the matching values decoded, got: %+v", disk)
+		}
+		break
+	}
+
+	for _, err := range ParseInto[int](context.Background(), p, bytes.NewReader(dataLine)) {
+		if err == nil {
+			t.Fatal("expected error for a non struct type")
+		}
+	}
+}
+
+func BenchmarkParseInto(b *testing.B) {
+	values := []*Value{
+		MustNewValue("maj", Number),
+		MustNewValue("min", Number),
+		MustNewValue("device", String)}
+
+	p, err := NewParser(values, LineRegex(`(\d+)\s+(\d+)\s+(.*?)\s+`))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for range ParseInto[unmarshalDisk](context.Background(), p, bytes.NewReader(dataLine)) {
+		}
+	}
+}
@@ -2,12 +2,15 @@ package rexon
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode"
 	"unsafe"
 )
 
@@ -18,6 +21,7 @@ type ValueType string
 
 const (
 	Number      ValueType = "number"
+	Integer     ValueType = "integer"
 	String      ValueType = "string"
 	Bool        ValueType = "bool"
 	Time        ValueType = "time"
@@ -40,7 +44,38 @@ const (
 	PiB = TiB * 1024
 )
 
+// ClockFormat is how a Duration clock with two parts as "05:30" is read
+type ClockFormat string
+
+const (
+	// ClockHourMinute reads two part clocks as h:mm, as printed by uptime. This is the default
+	ClockHourMinute ClockFormat = "h:mm"
+	// ClockMinuteSecond reads two part clocks as mm:ss, as printed in the ps etime and time columns
+	ClockMinuteSecond ClockFormat = "mm:ss"
+)
+
 var (
+	humanDurationUnits = map[string]time.Duration{
+		"d":       24 * time.Hour,
+		"day":     24 * time.Hour,
+		"days":    24 * time.Hour,
+		"h":       time.Hour,
+		"hr":      time.Hour,
+		"hrs":     time.Hour,
+		"hour":    time.Hour,
+		"hours":   time.Hour,
+		"m":       time.Minute,
+		"min":     time.Minute,
+		"mins":    time.Minute,
+		"minute":  time.Minute,
+		"minutes": time.Minute,
+		"s":       time.Second,
+		"sec":     time.Second,
+		"secs":    time.Second,
+		"second":  time.Second,
+		"seconds": time.Second,
+	}
+
 	rexUnit      = regexp.MustCompile(`([-+]?\d*\.?\d+)\s*([a-z,A-Z])?`)
 	rexIsUnit    = regexp.MustCompile(`[-+]?\d*\.?\d+\s*[a-z,A-Z]`)
 	digitalUnits = map[string]float64{
@@ -97,13 +132,23 @@ var (
 
 // Value represent each singular value to extract, parse and transform
 type Value struct {
-	name       string         // Value name
-	nullable   bool           // Nullable
-	valueType  ValueType      // ValueType
-	fromFormat string         // Format to convert from
-	toFormat   string         // Format to convert to
-	round      int            // Round when parsing numbers
-	regex      *regexp.Regexp // Regexp used to extract data
+	name       string           // Value name
+	path       []string         // JSON path for the value
+	nullable   bool             // Nullable
+	required   bool             // Required in the record
+	valueType  ValueType        // ValueType
+	fromFormat string           // Format to convert from
+	layouts    []string         // Time layouts to convert from
+	layout     atomic.Int32     // Index of the last matched time layout
+	toFormat   string           // Format to convert to
+	toLayout   string           // Time layout to convert to
+	round      int              // Round when parsing numbers
+	regex      *regexp.Regexp   // Regexp used to extract data
+	location   *time.Location   // Location of times without a zone
+	toLocation *time.Location   // Location to convert times to
+	inferYear  bool             // Infer the year of times without one
+	clock      func() time.Time // Reference clock to infer the year
+	shortClock ClockFormat      // Format of two part duration clocks
 }
 
 // ValueOpt functional options for Value
@@ -111,7 +156,11 @@ type ValueOpt func(*Value) (err error)
 
 // NewValue creates a new value parser
 func NewValue(name string, vt ValueType, options ...ValueOpt) (v *Value, err error) {
-	v = &Value{name: name, valueType: vt, round: 2, nullable: false}
+	v = &Value{name: name, valueType: vt, round: 2, nullable: false, location: time.UTC, clock: time.Now, shortClock: ClockHourMinute}
+	if v.path, err = jsonPath(name); err != nil {
+		return nil, err
+	}
+
 	for _, opt := range options {
 		if err = opt(v); err != nil {
 			return nil, err
@@ -129,16 +178,38 @@ func MustNewValue(name string, vt ValueType, options ...ValueOpt) (v *Value) {
 	return v
 }
 
+// Required sets the value as required, so records where it is missing or null are rejected
+func Required() (opt ValueOpt) {
+	return func(v *Value) (err error) {
+		v.required = true
+		return nil
+	}
+}
+
 // ValueRegex sets the regexp for this value parser
 func ValueRegex(expr string) (opt ValueOpt) {
 	return func(v *Value) (err error) {
-		r, err := regexp.Compile(expr)
+		r, err := compileRegex(expr)
 		v.regex = r
 		return err
 	}
 }
 
-// Round sets the round for this value parser, defaults to 2 if not specified
+// Path sets the JSON path for this value, defaults to the value name split by dots.
+// Keys with the "[]" suffix are arrays, so a "disks[].name" value is set in the last
+// element of the disks array if it does not have a name yet, or appended as a new element
+func Path(keys ...string) (opt ValueOpt) {
+	return func(v *Value) (err error) {
+		if len(keys) == 0 {
+			return fmt.Errorf("empty path for %s", v.name)
+		}
+		v.path = keys
+		return nil
+	}
+}
+
+// Round sets the decimal places Number and DigitalUnit values are rounded to, defaults to 2 if not specified.
+// Zero rounds to an integer and a negative round keeps the values unrounded
 func Round(round int) (opt ValueOpt) {
 	return func(v *Value) (err error) {
 		v.round = round
@@ -146,22 +217,73 @@ func Round(round int) (opt ValueOpt) {
 	}
 }
 
-// FromFormat sets the from format for this value parser
+// FromFormat sets the from format for this value parser.
+// Time formats are Go layouts, strftime formats as "%Y-%m-%d %H:%M:%S", the AutoFormat,
+// or the unix, unix_milli, unix_micro and unix_nano epochs with optional fractions and
+// epoch_auto that guesses the epoch unit from its magnitude
 func FromFormat(format string) (opt ValueOpt) {
 	return func(v *Value) (err error) {
+		if v.valueType == Time {
+			format, err = timeLayout(format, false)
+		}
 		v.fromFormat = format
-		return nil
+		return err
 	}
 }
 
-// ToFormat sets the destination format for this value parser
+// ToFormat sets the destination format for this value parser.
+// Time formats are also custom Go layouts or strftime formats
 func ToFormat(format string) (opt ValueOpt) {
 	return func(v *Value) (err error) {
 		v.toFormat = strings.ToLower(format)
+		if v.valueType != Time {
+			return nil
+		}
+
+		layout, err := timeLayout(format, true)
+		if err != nil {
+			return err
+		}
+		if isLayout(layout) {
+			v.toLayout = layout
+		}
 		return nil
 	}
 }
 
+// InferYear sets the year of Time values parsed without one, as in syslog timestamps,
+// to the latest year that does not place the time more than a day after the Clock
+func InferYear() (opt ValueOpt) {
+	return func(v *Value) (err error) {
+		v.inferYear = true
+		return nil
+	}
+}
+
+// Clock sets the reference clock used to InferYear, defaults to time.Now
+func Clock(now func() time.Time) (opt ValueOpt) {
+	return func(v *Value) (err error) {
+		if now == nil {
+			return fmt.Errorf("nil clock for %s", v.name)
+		}
+		v.clock = now
+		return nil
+	}
+}
+
+// ShortClock sets how Duration clocks with two parts are read, defaults to ClockHourMinute.
+// Clocks with three parts are always read as h:mm:ss
+func ShortClock(format ClockFormat) (opt ValueOpt) {
+	return func(v *Value) (err error) {
+		switch format {
+		case ClockHourMinute, ClockMinuteSecond:
+			v.shortClock = format
+			return nil
+		}
+		return fmt.Errorf("unsupported clock format %s", format)
+	}
+}
+
 // Nullable sets the value to null on parsing errors and ignores the error
 func Nullable() (opt ValueOpt) {
 	return func(v *Value) (err error) {
@@ -175,6 +297,11 @@ func (v *Value) Name() (name string) {
 	return v.name
 }
 
+// Path returns this value JSON path
+func (v *Value) Path() (path []string) {
+	return v.path
+}
+
 // Parse parses the value for the given byte. Uses the ValueRegex if specified to first extract the data
 func (v *Value) Parse(b []byte) (value interface{}, matched bool, err error) {
 
@@ -186,7 +313,7 @@ func (v *Value) Parse(b []byte) (value interface{}, matched bool, err error) {
 		}
 
 		if len(match) > 2 {
-			return nil, true, fmt.Errorf("parser: %s invalid number of matches for: %s", v.name, string(b))
+			return nil, true, v.parseError(b, ErrCaptureCount)
 		}
 
 		b = match[1]
@@ -197,6 +324,8 @@ func (v *Value) Parse(b []byte) (value interface{}, matched bool, err error) {
 		value, err = v.parseString(b)
 	case Number:
 		value, err = v.parseNumber(b)
+	case Integer:
+		value, err = v.parseInteger(b)
 	case Bool:
 		value, err = strconv.ParseBool(*(*string)(unsafe.Pointer(&b)))
 	case Time:
@@ -206,7 +335,7 @@ func (v *Value) Parse(b []byte) (value interface{}, matched bool, err error) {
 	case DigitalUnit:
 		value, err = v.parseUnit(b)
 	default:
-		err = fmt.Errorf("unsupported type %s for: %s", v.valueType, v.name)
+		err = fmt.Errorf("unsupported type %s", v.valueType)
 	}
 
 	// Set to null if we cannot parse and Nullable is specified
@@ -214,7 +343,16 @@ func (v *Value) Parse(b []byte) (value interface{}, matched bool, err error) {
 		return nil, true, nil
 	}
 
-	return value, true, err
+	if err != nil {
+		return value, true, v.parseError(b, err)
+	}
+
+	return value, true, nil
+}
+
+// parseError wraps the error into a ParseError for the input
+func (v *Value) parseError(b []byte, err error) (perr *ParseError) {
+	return &ParseError{Value: v.name, Type: v.valueType, Input: string(b), Err: err}
 }
 
 func (v *Value) parseString(b []byte) (value interface{}, err error) {
@@ -231,6 +369,7 @@ func (v *Value) parseString(b []byte) (value interface{}, err error) {
 // parseDuration parses a string representation of duration into a specified time unit or in a time.Duration
 func (v *Value) parseDuration(b []byte) (value interface{}, err error) {
 	b = bytes.ToLower(b)
+	raw := b
 
 	if !rexIsUnit.Match(b) {
 		// s := *(*string)(unsafe.Pointer(&b))
@@ -246,9 +385,20 @@ func (v *Value) parseDuration(b []byte) (value interface{}, err error) {
 	s := *(*string)(unsafe.Pointer(&b))
 	d, err := time.ParseDuration(s)
 	if err != nil {
-		return nil, err
+		var herr error
+		if d, herr = parseHumanDuration(*(*string)(unsafe.Pointer(&raw)), v.shortClock); herr != nil {
+			if errors.Is(herr, ErrUnknownUnit) {
+				return nil, herr
+			}
+			return nil, err
+		}
 	}
 
+	return v.convertDuration(d)
+}
+
+// convertDuration converts a time.Duration into the specified destination format
+func (v *Value) convertDuration(d time.Duration) (value interface{}, err error) {
 	switch v.toFormat {
 	case "nanoseconds", "nanosecond", "nano", "ns":
 		value = d.Nanoseconds()
@@ -263,25 +413,135 @@ func (v *Value) parseDuration(b []byte) (value interface{}, err error) {
 	case "string", "":
 		value = d.String()
 	default:
-		err = fmt.Errorf("unsupported destination format for %s: %s", v.name, v.toFormat)
+		err = fmt.Errorf("%w for destination: %s", ErrUnsupportedFormat, v.toFormat)
 	}
 
 	return value, err
 }
 
+// parseHumanDuration parses durations in the forms printed by common tools as
+// "10 days, 3:04", "1 day, 10 min", "2-03:04:05" or "03:04:05", where two part clocks are in the given format
+func parseHumanDuration(s string, short ClockFormat) (d time.Duration, err error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' })
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+
+	for i := 0; i < len(fields); i++ {
+		if strings.Contains(fields[i], ":") {
+			c, err := parseClock(fields[i], short)
+			if err != nil {
+				return 0, err
+			}
+			d += c
+			continue
+		}
+
+		num := strings.TrimRightFunc(fields[i], unicode.IsLetter)
+		unit := fields[i][len(num):]
+		if unit == "" && i+1 < len(fields) {
+			i++
+			unit = fields[i]
+		}
+
+		n, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return 0, err
+		}
+
+		mult, ok := humanDurationUnits[unit]
+		if !ok {
+			return 0, fmt.Errorf("%w %s in: %s", ErrUnknownUnit, unit, s)
+		}
+		d += time.Duration(n * float64(mult))
+	}
+
+	return d, nil
+}
+
+// parseClock parses a clock duration in the [d-]h:mm:ss form or with two parts in the given format
+func parseClock(s string, short ClockFormat) (d time.Duration, err error) {
+	if i := strings.IndexByte(s, '-'); i > 0 {
+		days, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(days) * 24 * time.Hour
+		s = s[i+1:]
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid clock duration: %s", s)
+	}
+
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	if len(parts) == 2 && short == ClockMinuteSecond {
+		units = units[1:]
+	}
+	for i := range parts {
+		n, err := strconv.ParseFloat(parts[i], 64)
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n * float64(units[i]))
+	}
+
+	return d, nil
+}
+
 // parseTime parses a string representation of time from the specified format into a specified format or in a time.Time
 func (v *Value) parseTime(b []byte) (value interface{}, err error) {
 	s := *(*string)(unsafe.Pointer(&b))
-	t, err := time.Parse(v.fromFormat, s)
+
+	var t time.Time
+	switch {
+	case v.layouts != nil:
+		t, err = v.parseLayouts(v.layouts, s)
+	case v.fromFormat == AutoFormat:
+		t, err = v.parseLayouts(autoLayouts, s)
+	default:
+		t, err = parseLayout(v.fromFormat, s, v.location)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if v.inferYear && t.Year() == 0 {
+		t = v.withYear(t)
+	}
+
+	if v.toLocation != nil {
+		t = t.In(v.toLocation)
+	}
+	return v.convertTime(t)
+}
+
+// withYear sets the latest year to the time that does not place it more than a day
+// after the clock, so December times read in January are set in the previous year
+func (v *Value) withYear(t time.Time) (y time.Time) {
+	limit := v.clock().Add(24 * time.Hour)
+	year := limit.In(v.location).Year()
+
+	for i := 0; i < 8; i++ {
+		y = time.Date(year-i, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), v.location)
+		// Skip February 29 in non leap years
+		if y.Day() == t.Day() && !y.After(limit) {
+			return y
+		}
+	}
+	return y
+}
+
+// convertTime converts a time.Time into the specified destination format
+func (v *Value) convertTime(t time.Time) (value interface{}, err error) {
 	switch v.toFormat {
 	case "unix":
 		value = t.Unix()
 	case "unix_milli":
 		value = t.UnixNano() / int64(time.Millisecond)
+	case "unix_micro":
+		value = t.UnixNano() / int64(time.Microsecond)
 	case "unix_nano":
 		value = t.UnixNano()
 	case "rfc3339":
@@ -289,7 +549,11 @@ func (v *Value) parseTime(b []byte) (value interface{}, err error) {
 	case "rfc3339nano", "string", "":
 		value = t.Format(time.RFC3339Nano)
 	default:
-		err = fmt.Errorf("unsupported destination format for %s: %s", v.name, v.toFormat)
+		if v.toLayout == "" {
+			err = fmt.Errorf("%w for destination: %s", ErrUnsupportedFormat, v.toFormat)
+			break
+		}
+		value = t.Format(v.toLayout)
 
 	}
 
@@ -303,13 +567,34 @@ func (v *Value) parseNumber(b []byte) (value interface{}, err error) {
 		return nil, err
 	}
 
-	if v.round < 1 {
-		return value, nil
+	if v.round < 0 {
+		return f, nil
 	}
 
 	return round(f, v.round), nil
 }
 
+// parseInteger parses an integer string representation into an int64,
+// or into an uint64 for positive values that overflow an int64
+func (v *Value) parseInteger(b []byte) (value interface{}, err error) {
+	s := *(*string)(unsafe.Pointer(&b))
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return i, nil
+	}
+
+	if ne, ok := err.(*strconv.NumError); !ok || ne.Err != strconv.ErrRange || strings.HasPrefix(s, "-") {
+		return nil, err
+	}
+
+	u, err := strconv.ParseUint(strings.TrimPrefix(s, "+"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
 // parseUnit parses a digital unit string representation into a float64 in
 // bytes or any other unit format
 func (v *Value) parseUnit(b []byte) (value float64, err error) {
@@ -317,7 +602,7 @@ func (v *Value) parseUnit(b []byte) (value float64, err error) {
 	b = bytes.ToLower(b)
 	match := rexUnit.FindSubmatch(b)
 	if match == nil {
-		return 0, fmt.Errorf("no digital unit match for %s: %s", v.name, string(b))
+		return 0, fmt.Errorf("%w for digital unit: %s", ErrNoMatch, string(b))
 	}
 
 	val, err := strconv.ParseFloat(*(*string)(unsafe.Pointer(&match[1])), 64)
@@ -332,17 +617,20 @@ func (v *Value) parseUnit(b []byte) (value float64, err error) {
 	}
 	unit, ok := digitalUnits[u]
 	if !ok {
-		return 0, fmt.Errorf("cannot parse unit for %s: %s", v.name, u)
+		return 0, fmt.Errorf("%w: %s", ErrUnknownUnit, u)
 	}
 	val = val * unit
 
 	// Convert to the specified unit
 	unit, ok = digitalUnits[v.toFormat]
 	if !ok {
-		return 0, fmt.Errorf("unsupported unit for %s: %s", v.name, v.toFormat)
+		return 0, fmt.Errorf("%w for destination: %s", ErrUnknownUnit, v.toFormat)
 	}
 
-	return round(float64(val/unit), v.round), nil
+	if v.round < 0 {
+		return val / unit, nil
+	}
+	return round(val/unit, v.round), nil
 }
 
 // Round a float to the specified precision
@@ -3,6 +3,7 @@ package rexon
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 var (
@@ -46,6 +47,31 @@ func TestValueParseNumber(t *testing.T) {
 	t.Log("value: ", reflect.TypeOf(value), value)
 }
 
+func TestValueParseRound(t *testing.T) {
+	tests := []struct {
+		value    *Value
+		input    string
+		expected float64
+	}{
+		{MustNewValue("a", Number), "2.345", 2.35},
+		{MustNewValue("a", Number, Round(0)), "2.5", 3},
+		{MustNewValue("a", Number, Round(-1)), "2.345", 2.345},
+		{MustNewValue("a", DigitalUnit, ToFormat("kb")), "2345b", 2.35},
+		{MustNewValue("a", DigitalUnit, ToFormat("kb"), Round(0)), "2500b", 3},
+		{MustNewValue("a", DigitalUnit, ToFormat("kb"), Round(-1)), "2345b", 2.345},
+	}
+
+	for _, test := range tests {
+		value, _, err := test.value.Parse([]byte(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Fatalf("expected %v for %s with round %d, got: %v", test.expected, test.input, test.value.round, value)
+		}
+	}
+}
+
 func TestValueParseDigital(t *testing.T) {
 	v, err := NewValue(
 		"digital",
@@ -98,3 +124,153 @@ func TestValueParseDuration(t *testing.T) {
 	}
 	t.Log("value: ", reflect.TypeOf(value), value)
 }
+
+func TestValueParseInteger(t *testing.T) {
+	v, err := NewValue("integer", Integer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		data     string
+		expected interface{}
+	}{
+		{"9007199254740993", int64(9007199254740993)},
+		{"-9223372036854775808", int64(-9223372036854775808)},
+		{"18446744073709551615", uint64(18446744073709551615)},
+		{"+18446744073709551615", uint64(18446744073709551615)},
+	}
+
+	for _, test := range tests {
+		value, ok, err := v.Parse([]byte(test.data))
+		if !ok || err != nil {
+			t.Fatal(ok, err)
+		}
+		if value != test.expected {
+			t.Fatalf("expected %v, got %v", test.expected, value)
+		}
+	}
+
+	for _, data := range []string{"18446744073709551616", "-9223372036854775809", "1.5"} {
+		if _, _, err = v.Parse([]byte(data)); err == nil {
+			t.Fatalf("expected error for %s", data)
+		}
+	}
+}
+
+func TestValueParseHumanDuration(t *testing.T) {
+	v, err := NewValue("duration", Duration, ToFormat("seconds"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		data     string
+		expected float64
+	}{
+		{"10 days,  3:04", 10*86400 + 3*3600 + 4*60},
+		{"1 day, 10 min", 86400 + 600},
+		{"2-03:04:05", 2*86400 + 3*3600 + 4*60 + 5},
+		{"00:00:02", 2},
+		{"45 min", 2700},
+		{"1h30m", 5400},
+		{"90", 90},
+	}
+
+	for _, test := range tests {
+		value, ok, err := v.Parse([]byte(test.data))
+		if !ok || err != nil {
+			t.Fatal(test.data, ok, err)
+		}
+		if value != test.expected {
+			t.Fatalf("expected %v for %s, got %v", test.expected, test.data, value)
+		}
+	}
+
+	if _, _, err = v.Parse([]byte("10 fortnights")); err == nil {
+		t.Fatal("expected error for invalid duration")
+	}
+}
+
+func TestValueParseShortClock(t *testing.T) {
+	tests := []struct {
+		format   ClockFormat
+		data     string
+		expected float64
+	}{
+		{ClockHourMinute, "05:30", 5*3600 + 30*60},
+		{ClockMinuteSecond, "05:30", 5*60 + 30},
+		{ClockMinuteSecond, "01:05:30", 3600 + 5*60 + 30},
+		{ClockMinuteSecond, "2-01:05:30", 2*86400 + 3600 + 5*60 + 30},
+	}
+
+	for _, test := range tests {
+		v, err := NewValue("etime", Duration, ToFormat("seconds"), ShortClock(test.format))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value, ok, err := v.Parse([]byte(test.data))
+		if !ok || err != nil {
+			t.Fatal(test.data, ok, err)
+		}
+		if value != test.expected {
+			t.Fatalf("expected %v for %s as %s, got %v", test.expected, test.data, test.format, value)
+		}
+	}
+
+	if _, err := NewValue("etime", Duration, ShortClock("ss")); err == nil {
+		t.Fatal("expected error for an unsupported clock format")
+	}
+}
+
+func TestValueParseTimeInferYear(t *testing.T) {
+	tests := []struct {
+		now      time.Time
+		input    string
+		expected string
+	}{
+		{time.Date(2018, 10, 16, 10, 0, 0, 0, time.UTC), "Oct 16 09:12:01", "2018-10-16T09:12:01Z"},
+		{time.Date(2018, 10, 16, 10, 0, 0, 0, time.UTC), "Oct 16 23:12:01", "2018-10-16T23:12:01Z"},
+		{time.Date(2018, 10, 16, 10, 0, 0, 0, time.UTC), "Nov  2 09:12:01", "2017-11-02T09:12:01Z"},
+		{time.Date(2019, 1, 2, 10, 0, 0, 0, time.UTC), "Dec 31 23:59:59", "2018-12-31T23:59:59Z"},
+		{time.Date(2018, 12, 31, 23, 59, 0, 0, time.UTC), "Jan  1 00:00:30", "2019-01-01T00:00:30Z"},
+		{time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC), "Feb 29 12:00:00", "2016-02-29T12:00:00Z"},
+	}
+
+	for _, test := range tests {
+		now := test.now
+		v, err := NewValue("time", Time, FromFormat(time.Stamp), ToFormat("rfc3339"),
+			InferYear(), Clock(func() time.Time { return now }))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value, _, err := v.Parse([]byte(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Fatalf("expected %s for %s at %s, got: %v", test.expected, test.input, now, value)
+		}
+	}
+
+	v, err := NewValue("time", Time, FromFormat(time.Stamp), ToFormat("unix"),
+		InferYear(), SourceLocation("America/Sao_Paulo"),
+		Clock(func() time.Time { return time.Date(2018, 10, 16, 10, 0, 0, 0, time.UTC) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, _, err := v.Parse([]byte("Oct 16 06:12:01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != int64(1539681121) {
+		t.Fatalf("expected 1539681121, got: %v", value)
+	}
+
+	if _, err = NewValue("time", Time, Clock(nil)); err == nil {
+		t.Fatal("expected error for a nil clock")
+	}
+}